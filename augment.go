@@ -0,0 +1,202 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"cmp"
+	"iter"
+)
+
+// A Monoid summarizes key and data pairs.  Measure gives the summary
+// of a single pair, and Combine gives the summary of two adjacent runs
+// of pairs, in key order, from their summaries.  Combine must be
+// associative, with identity Identity, but need not be commutative.
+type Monoid[K any, D any, M any] struct {
+	Measure  func(k K, d D) M
+	Combine  func(a, b M) M
+	Identity M
+}
+
+// An Augmented tree is a persistent AVL tree mapping keys K to data D
+// that also keeps, in every node, the summary under a Monoid of the
+// pairs in that node's subtree.  That lets Aggregate summarize any
+// range of keys in O(log n) time.
+//
+// Summaries are maintained by running each mutation as if by a Builder
+// with a fresh edit, so that every node the mutation creates or copies,
+// including those moved by rotations, is marked as its own; those are
+// exactly the nodes whose subtrees changed, and their summaries are then
+// recomputed bottom-up, in time proportional to the number copied.
+type Augmented[K any, D any, M any, C Comparer[K]] struct {
+	t   Tree[K, augItem[D, M], C]
+	mon Monoid[K, D, M]
+}
+
+// An augItem is the data of an Augmented tree's node: the user's data,
+// and the summary of the node's subtree.
+type augItem[D any, M any] struct {
+	data D
+	sum  M
+}
+
+// NewAugmented returns an empty Augmented tree summarized by mon,
+// whose keys are ordered by their Compare method.
+func NewAugmented[K Comparable[K], D any, M any](mon Monoid[K, D, M]) *Augmented[K, D, M, MethodCompare[K]] {
+	return &Augmented[K, D, M, MethodCompare[K]]{mon: mon}
+}
+
+// NewAugmentedOrdered is like NewAugmented, but orders keys by cmp.Compare.
+func NewAugmentedOrdered[K cmp.Ordered, D any, M any](mon Monoid[K, D, M]) *Augmented[K, D, M, OrderedCompare[K]] {
+	return &Augmented[K, D, M, OrderedCompare[K]]{mon: mon}
+}
+
+// NewAugmentedFunc is like NewAugmented, but orders keys by cmp.
+func NewAugmentedFunc[K any, D any, M any](mon Monoid[K, D, M], cmp func(a, b K) int) *Augmented[K, D, M, FuncCompare[K]] {
+	a := &Augmented[K, D, M, FuncCompare[K]]{mon: mon}
+	a.t.order = FuncCompare[K]{cmp}
+	return a
+}
+
+// sum returns the summary of the subtree n.
+func (a *Augmented[K, D, M, C]) sum(n *node[K, augItem[D, M]]) M {
+	if n == nil {
+		return a.mon.Identity
+	}
+	return n.data.sum
+}
+
+// fixup recomputes the summaries of the nodes owned by e in the
+// subtree n, and releases them from e, so they are never modified
+// in place again.  A node not owned by e was not changed, and
+// neither was any node below it.
+func (a *Augmented[K, D, M, C]) fixup(n *node[K, augItem[D, M]], e *edit) {
+	if n == nil || n.edit != e {
+		return
+	}
+	a.fixup(n.left, e)
+	a.fixup(n.right, e)
+	n.data.sum = a.mon.Combine(a.mon.Combine(a.sum(n.left), a.mon.Measure(n.key, n.data.data)), a.sum(n.right))
+	n.edit = nil
+}
+
+// Insert is like Tree.Insert.
+func (a *Augmented[K, D, M, C]) Insert(x K, data D) D {
+	e := new(edit)
+	o := a.t.insert(x, augItem[D, M]{data: data}, e)
+	a.fixup(a.t.root, e)
+	return o.data
+}
+
+// Delete is like Tree.Delete.
+func (a *Augmented[K, D, M, C]) Delete(x K) D {
+	d, _ := a.DeleteOk(x)
+	return d
+}
+
+// DeleteOk is like Tree.DeleteOk.
+func (a *Augmented[K, D, M, C]) DeleteOk(x K) (D, bool) {
+	e := new(edit)
+	d, ok := a.t.deleteOk(x, e)
+	a.fixup(a.t.root, e)
+	return d.data, ok
+}
+
+// DeleteMin is like Tree.DeleteMin.
+func (a *Augmented[K, D, M, C]) DeleteMin() (K, D) {
+	k, d, _ := a.DeleteMinOk()
+	return k, d
+}
+
+// DeleteMinOk is like Tree.DeleteMinOk.
+func (a *Augmented[K, D, M, C]) DeleteMinOk() (K, D, bool) {
+	e := new(edit)
+	k, d, ok := a.t.deleteMinOk(e)
+	a.fixup(a.t.root, e)
+	return k, d.data, ok
+}
+
+// DeleteMax is like Tree.DeleteMax.
+func (a *Augmented[K, D, M, C]) DeleteMax() (K, D) {
+	k, d, _ := a.DeleteMaxOk()
+	return k, d
+}
+
+// DeleteMaxOk is like Tree.DeleteMaxOk.
+func (a *Augmented[K, D, M, C]) DeleteMaxOk() (K, D, bool) {
+	e := new(edit)
+	k, d, ok := a.t.deleteMaxOk(e)
+	a.fixup(a.t.root, e)
+	return k, d.data, ok
+}
+
+// Get is like Tree.Get.
+func (a *Augmented[K, D, M, C]) Get(x K) (D, bool) {
+	d, ok := a.t.Get(x)
+	return d.data, ok
+}
+
+func (a *Augmented[K, D, M, C]) Size() int {
+	return a.t.Size()
+}
+
+func (a *Augmented[K, D, M, C]) Copy() *Augmented[K, D, M, C] {
+	c := *a
+	return &c
+}
+
+// All returns an iterator over the key and data pairs in a,
+// ordered from smallest to largest key.
+func (a *Augmented[K, D, M, C]) All() iter.Seq2[K, D] {
+	return func(yield func(k K, d D) bool) {
+		a.t.root.doAll2Flat(func(k K, d augItem[D, M]) bool {
+			return yield(k, d.data)
+		})
+	}
+}
+
+// Summary returns the summary of every pair in a, in O(1) time.
+func (a *Augmented[K, D, M, C]) Summary() M {
+	return a.sum(a.t.root)
+}
+
+// Aggregate returns the summary of the pairs in a with keys
+// between lo and hi, in O(log n) time.
+func (a *Augmented[K, D, M, C]) Aggregate(lo, hi Bound[K]) M {
+	compare := a.t.cmp()
+	n := a.t.root
+	// Descend to the highest node in range; the rest of the range is below it.
+	for n != nil {
+		if !aboveLo(lo, n.key, compare) {
+			n = n.right
+		} else if !belowHi(hi, n.key, compare) {
+			n = n.left
+		} else {
+			break
+		}
+	}
+	if n == nil {
+		return a.mon.Identity
+	}
+	m := a.mon.Measure(n.key, n.data.data)
+	// The part of the left subtree above lo.
+	for l := n.left; l != nil; {
+		if aboveLo(lo, l.key, compare) {
+			m = a.mon.Combine(a.mon.Combine(a.mon.Measure(l.key, l.data.data), a.sum(l.right)), m)
+			l = l.left
+		} else {
+			l = l.right
+		}
+	}
+	// The part of the right subtree below hi.
+	for r := n.right; r != nil; {
+		if belowHi(hi, r.key, compare) {
+			m = a.mon.Combine(m, a.mon.Combine(a.sum(r.left), a.mon.Measure(r.key, r.data.data)))
+			r = r.right
+		} else {
+			r = r.left
+		}
+	}
+	return m
+}
@@ -0,0 +1,107 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+)
+
+// concat is a non-commutative monoid, so summaries must be in key order.
+var concat = Monoid[Int, int, string]{
+	Measure:  func(k Int, d int) string { return fmt.Sprintf("%d:%d,", k, d) },
+	Combine:  func(a, b string) string { return a + b },
+	Identity: "",
+}
+
+func TestAugmented(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	a := NewAugmented(concat)
+	var old []*Augmented[Int, int, string, MethodCompare[Int]]
+	var oldSums []string
+	for i := 0; i < 2000; i++ {
+		k := Int(r.IntN(300))
+		switch r.IntN(5) {
+		case 0:
+			a.Delete(k)
+		case 1:
+			nonempty := a.Size() > 0
+			switch r.IntN(4) {
+			case 0:
+				a.DeleteMin()
+			case 1:
+				a.DeleteMax()
+			case 2:
+				if _, _, ok := a.DeleteMinOk(); ok != nonempty {
+					t.Fatalf("DeleteMinOk of a tree of size %d returned %v", a.Size(), ok)
+				}
+			case 3:
+				if _, _, ok := a.DeleteMaxOk(); ok != nonempty {
+					t.Fatalf("DeleteMaxOk of a tree of size %d returned %v", a.Size(), ok)
+				}
+			}
+		default:
+			a.Insert(k, i)
+		}
+		checkSums(t, a, a.t.root)
+		if i%100 == 0 {
+			old = append(old, a.Copy())
+			oldSums = append(oldSums, a.Summary())
+		}
+		for range 3 {
+			lo, hi := randomBound(r, 320), randomBound(r, 320)
+			want := ""
+			for k, d := range a.t.Range(lo, hi) {
+				want += concat.Measure(k, d.data)
+			}
+			if got := a.Aggregate(lo, hi); got != want {
+				t.Fatalf("op %d: Aggregate(%v, %v) = %s, want %s", i, lo, hi, got, want)
+			}
+		}
+	}
+	for i, o := range old {
+		if o.Summary() != oldSums[i] {
+			t.Errorf("version %d changed", i)
+		}
+		checkSums(t, o, o.t.root)
+	}
+
+	counts := NewAugmentedOrdered(Monoid[float64, float64, float64]{
+		Measure: func(k, d float64) float64 { return d },
+		Combine: func(a, b float64) float64 { return a + b },
+	})
+	for i := range 100 {
+		counts.Insert(float64(i)/10, 1)
+	}
+	if n := counts.Aggregate(Inclusive(2.0), Exclusive(5.0)); n != 30 {
+		t.Errorf("count of [2, 5) = %v", n)
+	}
+}
+
+func randomBound(r *rand.Rand, n int) Bound[Int] {
+	switch r.IntN(3) {
+	case 0:
+		return Unbounded[Int]()
+	case 1:
+		return Inclusive(Int(r.IntN(n)))
+	}
+	return Exclusive(Int(r.IntN(n)))
+}
+
+// checkSums checks the summary in every node of n, and returns n's.
+func checkSums[C Comparer[Int]](t *testing.T, a *Augmented[Int, int, string, C], n *node[Int, augItem[int, string]]) string {
+	if n == nil {
+		return ""
+	}
+	want := checkSums(t, a, n.left) + concat.Measure(n.key, n.data.data) + checkSums(t, a, n.right)
+	if n.data.sum != want {
+		t.Fatalf("summary at %d is %q, want %q", n.key, n.data.sum, want)
+	}
+	if n.edit != nil {
+		t.Fatalf("node %d is still owned by an edit", n.key)
+	}
+	return want
+}
@@ -5,16 +5,56 @@
 package iter_test
 
 import (
+	"cmp"
 	"fmt"
+	"iter"
 )
 
 type Comparable[T any] interface {
 	Compare(T) int
 }
 
-type T[K Comparable[K], D any] struct {
-	root *node[K, D]
-	size int
+// A Tree is a persistent AVL tree mapping keys K to data D,
+// with keys ordered by the comparer C.  Most code will use
+// one of its aliases, T, Ordered or Func.
+type Tree[K any, D any, C Comparer[K]] struct {
+	root  *node[K, D]
+	size  int
+	order C
+}
+
+// T is a Tree whose keys are ordered by their Compare method.
+// The zero T is an empty tree, ready to use.
+type T[K Comparable[K], D any] = Tree[K, D, MethodCompare[K]]
+
+// Ordered is a Tree whose keys are ordered by cmp.Compare.
+// The zero Ordered is an empty tree, ready to use.
+type Ordered[K cmp.Ordered, D any] = Tree[K, D, OrderedCompare[K]]
+
+// Func is a Tree whose keys are ordered by a comparison function.
+// Use NewFunc to create one.
+type Func[K any, D any] = Tree[K, D, FuncCompare[K]]
+
+// NewFunc returns an empty Func ordered by cmp, which must be a
+// strict weak ordering like those used by slices.SortFunc.
+func NewFunc[K any, D any](cmp func(a, b K) int) *Func[K, D] {
+	return &Func[K, D]{order: FuncCompare[K]{cmp}}
+}
+
+// empty returns an empty tree with the same ordering as t.
+func (t *Tree[K, D, C]) empty() *Tree[K, D, C] {
+	return &Tree[K, D, C]{order: t.order}
+}
+
+// withRoot returns a tree with the same ordering as t and root r.
+func (t *Tree[K, D, C]) withRoot(r *node[K, D]) *Tree[K, D, C] {
+	u := &Tree[K, D, C]{root: r, size: r.size(), order: t.order}
+	return u.check()
+}
+
+// cmp returns the comparison function for t's keys.
+func (t *Tree[K, D, C]) cmp() func(a, b K) int {
+	return t.order.Compare
 }
 
 type String string
@@ -30,19 +70,30 @@ func (s String) Compare(t String) int {
 }
 
 // IsSingle returns true iff t is empty.
-func (t *T[K, D]) IsEmpty() bool {
+func (t *Tree[K, D, C]) IsEmpty() bool {
 	return t.root == nil
 }
 
 // IsSingle returns true iff t is a singleton (leaf).
-func (t *T[K, D]) IsSingle() bool {
+func (t *Tree[K, D, C]) IsSingle() bool {
 	return t.root != nil && t.root.isLeaf()
 }
 
 // Find returns the data associated with x in the tree, or
 // nil if x is not in the tree.
-func (t *T[K, D]) Find(x K) D {
-	return t.root.find(x).nilOrData()
+func (t *Tree[K, D, C]) Find(x K) D {
+	return t.root.find(x, t.cmp()).nilOrData()
+}
+
+// Get returns the data associated with x in the tree and true,
+// or the zero value and false if x is not in the tree.
+func (t *Tree[K, D, C]) Get(x K) (D, bool) {
+	return t.root.find(x, t.cmp()).dataAndOk()
+}
+
+// Contains returns true iff x is a key in the tree.
+func (t *Tree[K, D, C]) Contains(x K) bool {
+	return t.root.find(x, t.cmp()) != nil
 }
 
 // Insert either adds x to the tree if x was not previously
@@ -50,15 +101,20 @@ func (t *T[K, D]) Find(x K) D {
 // x was already a key in the tree.  The previous data associated
 // with x is returned, and is nil if x was not previously a
 // key in the tree.
-func (t *T[K, D]) Insert(x K, data D) D {
+func (t *Tree[K, D, C]) Insert(x K, data D) D {
+	return t.insert(x, data, nil)
+}
+
+// insert is Insert, modifying in place any nodes owned by e.
+func (t *Tree[K, D, C]) insert(x K, data D, e *edit) D {
 	n := t.root
 	var newroot *node[K, D]
 	var o *node[K, D]
 	if n == nil {
-		n = makeNode[K, D](x)
+		n = makeNode[K, D](x, e)
 		newroot = n
 	} else {
-		newroot, n, o = n.aInsert(x)
+		newroot, n, o = n.aInsert(x, t.cmp(), e)
 	}
 	var r D
 	if o != nil {
@@ -68,66 +124,92 @@ func (t *T[K, D]) Insert(x K, data D) D {
 	}
 	n.data = data
 	t.root = newroot
+	t.check()
 	return r
 }
 
-func (t *T[K, D]) Copy() *T[K, D] {
+func (t *Tree[K, D, C]) Copy() *Tree[K, D, C] {
 	u := *t
 	return &u
 }
 
-func (t *T[K, D]) Delete(x K) D {
+func (t *Tree[K, D, C]) Delete(x K) D {
+	d, _ := t.DeleteOk(x)
+	return d
+}
+
+// DeleteOk removes x from the tree, returning its data and true,
+// or the zero value and false if x was not in the tree.
+func (t *Tree[K, D, C]) DeleteOk(x K) (D, bool) {
+	return t.deleteOk(x, nil)
+}
+
+func (t *Tree[K, D, C]) deleteOk(x K, e *edit) (D, bool) {
 	n := t.root
-	var zero D
 	if n == nil {
-		return zero
+		return zero[D](), false
 	}
-	d, s := n.aDelete(x)
+	d, s := n.aDelete(x, t.cmp(), e)
 	if d == nil {
-		return zero
+		return zero[D](), false
 	}
 	t.root = s
 	t.size--
-	return d.data
+	t.check()
+	return d.data, true
+}
+
+func (t *Tree[K, D, C]) DeleteMin() (K, D) {
+	k, d, _ := t.DeleteMinOk()
+	return k, d
+}
+
+// DeleteMinOk removes the minimum element of t, returning its key,
+// data and true, or zero values and false if t is empty.
+func (t *Tree[K, D, C]) DeleteMinOk() (K, D, bool) {
+	return t.deleteMinOk(nil)
 }
 
-func (t *T[K, D]) DeleteMin() (K, D) {
+func (t *Tree[K, D, C]) deleteMinOk(e *edit) (K, D, bool) {
 	n := t.root
-	var zk K
-	var zd D
 	if n == nil {
-		return zk, zd
-	}
-	d, s := n.aDeleteMin()
-	if d == nil {
-		return zk, zd
+		return zero[K](), zero[D](), false
 	}
+	d, s := n.aDeleteMin(e)
 	t.root = s
 	t.size--
-	return d.key, d.data
+	t.check()
+	return d.key, d.data, true
+}
+
+func (t *Tree[K, D, C]) DeleteMax() (K, D) {
+	k, d, _ := t.DeleteMaxOk()
+	return k, d
+}
+
+// DeleteMaxOk removes the maximum element of t, returning its key,
+// data and true, or zero values and false if t is empty.
+func (t *Tree[K, D, C]) DeleteMaxOk() (K, D, bool) {
+	return t.deleteMaxOk(nil)
 }
 
-func (t *T[K, D]) DeleteMax() (K, D) {
+func (t *Tree[K, D, C]) deleteMaxOk(e *edit) (K, D, bool) {
 	n := t.root
-	var zk K
-	var zd D
 	if n == nil {
-		return zk, zd
-	}
-	d, s := n.aDeleteMax()
-	if d == nil {
-		return zk, zd
+		return zero[K](), zero[D](), false
 	}
+	d, s := n.aDeleteMax(e)
 	t.root = s
 	t.size--
-	return d.key, d.data
+	t.check()
+	return d.key, d.data, true
 }
 
-func (t *T[K, D]) Size() int {
+func (t *Tree[K, D, C]) Size() int {
 	return t.size
 }
 
-type Iter[K Comparable[K], D any] struct {
+type Iter[K any, D any] struct {
 	it iterator[K, D]
 }
 
@@ -145,38 +227,68 @@ func (it *Iter[K, D]) More() bool {
 
 // Min returns the minimum element of t.
 // If t is empty, then (nil, nil) is returned.
-func (t *T[K, D]) Min() (k K, d D) {
+func (t *Tree[K, D, C]) Min() (k K, d D) {
 	return t.root.minimum().nilOrKeyAndData()
 }
 
 // Max returns the maximum element of t.
 // If t is empty, then (nil, nil) is returned.
-func (t *T[K, D]) Max() (k K, d D) {
+func (t *Tree[K, D, C]) Max() (k K, d D) {
 	return t.root.maximum().nilOrKeyAndData()
 }
 
 // Glb returns the greatest-lower-bound-exclusive of x and the associated
 // data.  If x has no glb in the tree, then (nil, nil) is returned.
-func (t *T[K, D]) Glb(x K) (k K, d D) {
-	return t.root.glb(x, false).nilOrKeyAndData()
+func (t *Tree[K, D, C]) Glb(x K) (k K, d D) {
+	return t.root.glb(x, false, t.cmp()).nilOrKeyAndData()
 }
 
 // GlbEq returns the greatest-lower-bound-inclusive of x and the associated
 // data.  If x has no glbEQ in the tree, then (nil, nil) is returned.
-func (t *T[K, D]) GlbEq(x K) (k K, d D) {
-	return t.root.glb(x, true).nilOrKeyAndData()
+func (t *Tree[K, D, C]) GlbEq(x K) (k K, d D) {
+	return t.root.glb(x, true, t.cmp()).nilOrKeyAndData()
 }
 
 // Lub returns the least-upper-bound-exclusive of x and the associated
 // data.  If x has no lub in the tree, then (nil, nil) is returned.
-func (t *T[K, D]) Lub(x K) (k K, d D) {
-	return t.root.lub(x, false).nilOrKeyAndData()
+func (t *Tree[K, D, C]) Lub(x K) (k K, d D) {
+	return t.root.lub(x, false, t.cmp()).nilOrKeyAndData()
 }
 
 // LubEq returns the least-upper-bound-inclusive of x and the associated
 // data.  If x has no lubEq in the tree, then (nil, nil) is returned.
-func (t *T[K, D]) LubEq(x K) (k K, d D) {
-	return t.root.lub(x, true).nilOrKeyAndData()
+func (t *Tree[K, D, C]) LubEq(x K) (k K, d D) {
+	return t.root.lub(x, true, t.cmp()).nilOrKeyAndData()
+}
+
+// MinOk is like Min, but also returns false if t is empty.
+func (t *Tree[K, D, C]) MinOk() (K, D, bool) {
+	return t.root.minimum().keyDataAndOk()
+}
+
+// MaxOk is like Max, but also returns false if t is empty.
+func (t *Tree[K, D, C]) MaxOk() (K, D, bool) {
+	return t.root.maximum().keyDataAndOk()
+}
+
+// GlbOk is like Glb, but also returns false if x has no glb in the tree.
+func (t *Tree[K, D, C]) GlbOk(x K) (K, D, bool) {
+	return t.root.glb(x, false, t.cmp()).keyDataAndOk()
+}
+
+// GlbEqOk is like GlbEq, but also returns false if x has no glbEq in the tree.
+func (t *Tree[K, D, C]) GlbEqOk(x K) (K, D, bool) {
+	return t.root.glb(x, true, t.cmp()).keyDataAndOk()
+}
+
+// LubOk is like Lub, but also returns false if x has no lub in the tree.
+func (t *Tree[K, D, C]) LubOk(x K) (K, D, bool) {
+	return t.root.lub(x, false, t.cmp()).keyDataAndOk()
+}
+
+// LubEqOk is like LubEq, but also returns false if x has no lubEq in the tree.
+func (t *Tree[K, D, C]) LubEqOk(x K) (K, D, bool) {
+	return t.root.lub(x, true, t.cmp()).keyDataAndOk()
 }
 
 // This doesn't build with go1.4, sigh
@@ -197,11 +309,11 @@ func (t *T[K, D]) LubEq(x K) (k K, d D) {
 // 	return b.String()
 // }
 
-func (t *T[K, D]) ToIter() Iter[K, D] {
+func (t *Tree[K, D, C]) ToIter() Iter[K, D] {
 	return Iter[K, D]{it: t.root.iterator()}
 }
 
-func (t *T[K, D]) Iter2() func() (K, D, bool) {
+func (t *Tree[K, D, C]) Iter2() func() (K, D, bool) {
 	I := t.ToIter()
 	return func() (K, D, bool) {
 		if I.More() {
@@ -214,7 +326,7 @@ func (t *T[K, D]) Iter2() func() (K, D, bool) {
 	}
 }
 
-func (t *T[K, D]) Iter() func() (K, bool) {
+func (t *Tree[K, D, C]) Iter() func() (K, bool) {
 	I := t.ToIter()
 	return func() (K, bool) {
 		if I.More() {
@@ -226,7 +338,7 @@ func (t *T[K, D]) Iter() func() (K, bool) {
 	}
 }
 
-func (t *T[K, D]) String() string {
+func (t *Tree[K, D, C]) String() string {
 	var b string
 	first := true
 	for it := t.ToIter(); it.More(); {
@@ -243,228 +355,189 @@ func (t *T[K, D]) String() string {
 	return b
 }
 
-func (t *T[K, D]) Equiv(u *T[K, D], eqv func(x, y D) bool) bool {
+func (t *Tree[K, D, C]) Equiv(u *Tree[K, D, C], eqv func(x, y D) bool) bool {
 	if t == u {
 		return true
 	}
 	if t.Size() != u.Size() {
 		return false
 	}
-	return t.root.equiv(u.root, eqv)
+	for range DiffFunc(t, u, eqv) {
+		return false
+	}
+	return true
 }
 
 // VisitInOrder applies f to the key and ComparableStringerata pairs in t,
 // with keys ordered from smallest to largest.
-func (t *T[K, D]) VisitInOrder(f func(K, D)) {
+func (t *Tree[K, D, C]) VisitInOrder(f func(K, D)) {
 	if t.root == nil {
 		return
 	}
 	t.root.visitInOrder(f)
 }
 
-func (t *T[K, D]) DoAll2(yield func(k K, d D) bool) {
+func (t *Tree[K, D, C]) DoAll2(yield func(k K, d D) bool) {
 	t.root.doAll2(yield)
 }
 
-func (t *T[K, D]) DoAll2Flat(yield func(k K, d D) bool) {
+func (t *Tree[K, D, C]) DoAll2Flat(yield func(k K, d D) bool) {
 	t.root.doAll2Flat(yield)
 }
 
-func (t *T[K, D]) DoAll2FlatFilter(yield, filter func(k K, d D) bool) {
+func (t *Tree[K, D, C]) DoAll2FlatFilter(yield, filter func(k K, d D) bool) {
 	t.root.doAll2FlatFilter(yield, filter)
 }
 
-func (t *T[K, D]) DoAll2FlatFunc() func(func(K, D) bool) {
+func (t *Tree[K, D, C]) DoAll2FlatFunc() func(func(K, D) bool) {
 	return func(yield func(k K, d D) bool) {
 		t.root.doAll2Flat(yield)
 	}
 }
-func (t *T[K, D]) DoAll2FlatFilterFunc(filter func(k K, d D) bool) func(func(K, D) bool) {
+func (t *Tree[K, D, C]) DoAll2FlatFilterFunc(filter func(k K, d D) bool) func(func(K, D) bool) {
 	return func(yield func(k K, d D) bool) {
 		t.root.doAll2FlatFilter(yield, filter)
 	}
 }
 
-func (t *T[K, D]) DoAll(yield func(k K) bool) {
+// All returns an iterator over the key and data pairs in t,
+// with keys ordered from smallest to largest.
+func (t *Tree[K, D, C]) All() iter.Seq2[K, D] {
+	return func(yield func(k K, d D) bool) {
+		t.root.doAll2Flat(yield)
+	}
+}
+
+// Keys returns an iterator over the keys in t, from smallest to largest.
+func (t *Tree[K, D, C]) Keys() iter.Seq[K] {
+	return func(yield func(k K) bool) {
+		t.root.doAll2Flat(func(k K, _ D) bool { return yield(k) })
+	}
+}
+
+// Values returns an iterator over the data in t, ordered by key
+// from smallest to largest.
+func (t *Tree[K, D, C]) Values() iter.Seq[D] {
+	return func(yield func(d D) bool) {
+		t.root.doAll2Flat(func(_ K, d D) bool { return yield(d) })
+	}
+}
+
+// Backward returns an iterator over the key and data pairs in t,
+// with keys ordered from largest to smallest.
+func (t *Tree[K, D, C]) Backward() iter.Seq2[K, D] {
+	return func(yield func(k K, d D) bool) {
+		t.root.doAll2FlatBackward(yield)
+	}
+}
+
+func (t *Tree[K, D, C]) DoAll(yield func(k K) bool) {
 	t.root.doAll(yield)
 }
 
-func (t *T[K, D]) DoAllFunc() func(yield func(k K) bool) {
+func (t *Tree[K, D, C]) DoAllFunc() func(yield func(k K) bool) {
 	return func(yield func(k K) bool) {
 		t.root.doAll(yield)
 	}
 }
 
-func (t *T[K, D]) DoAll2Func() func(yield func(k K, d D) bool) {
+func (t *Tree[K, D, C]) DoAll2Func() func(yield func(k K, d D) bool) {
 	return func(yield func(k K, d D) bool) {
 		t.root.doAll2(yield)
 	}
 }
 
-func (t *T[K, D]) DoAll_(yield func(d D) bool) {
+func (t *Tree[K, D, C]) DoAll_(yield func(d D) bool) {
 	t.root.doAll_(yield)
 }
 
 // DoAllTwice is a BAD iterator, it will call yield after it returns false.
-func (t *T[K, D]) DoAllTwice(yield func(k K) bool) {
+func (t *Tree[K, D, C]) DoAllTwice(yield func(k K) bool) {
 	t.root.doAll(yield)
 	t.root.doAll(yield)
 }
 
-// Intersection returns the the intersection of T and U, with data modified
-// by the result of f(t.data, u.data); if non-nil/zero, that is the stored value,
-// if nil, then the entry is not added.  If f is nil, then the data from the
-// smaller set is what will be used (this maximizes sharing, if that result
-// is acceptable).
-func Intersection[K Comparable[K], D comparable](t, u *T[K, D], f func(x, y D) D) *T[K, D] {
+// Intersection returns the the intersection of t and u, with data modified
+// by the result of f(t.data, u.data); if f returns true, then its data is the
+// stored value, if false, then the entry is not added.  If f is nil, then the
+// data from the smaller set is what will be used (this maximizes sharing, if
+// that result is acceptable).  Membership is decided by the presence of keys,
+// so zero data is a legitimate value.
+//
+// The result is computed by splitting and joining subtrees, in
+// O(m log(n/m + 1)) time for sizes m <= n, and untouched subtrees
+// of the inputs are shared with the result.
+func Intersection[K any, D any, C Comparer[K]](t, u *Tree[K, D, C], f func(x, y D) (D, bool)) *Tree[K, D, C] {
 	if t.Size() == 0 || u.Size() == 0 {
-		return &T[K, D]{}
-	}
-
-	// For faster execution and less allocation, prefer t smaller, iterate over t.
-	if t.Size() <= u.Size() {
-		v := t.Copy()
-		for it := t.ToIter(); it.More(); {
-			k, d := it.Next()
-			e := u.Find(k)
-			if e == zero[D]() {
-				v.Delete(k)
-				continue
-			}
-			if f == nil {
-				continue
-			}
-			if c := f(d, e); c != d {
-				if c == zero[D]() {
-					v.Delete(k)
-				} else {
-					v.Insert(k, c)
-				}
-			}
-		}
-		return v
-	}
-	v := u.Copy()
-	for it := u.ToIter(); it.More(); {
-		k, e := it.Next()
-		d := t.Find(k)
-		if d == zero[D]() {
-			v.Delete(k)
-			continue
-		}
-		if f == nil {
-			continue
-		}
-		if c := f(d, e); c != d {
-			if c == zero[D]() {
-				v.Delete(k)
-			} else {
-				v.Insert(k, c)
-			}
-		}
+		return t.empty()
 	}
-
-	return v
+	if f == nil && t.Size() > u.Size() {
+		t, u = u, t
+	}
+	r := intersection(t.root, u.root, f, t.cmp(), nil)
+	return t.withRoot(r)
 }
 
 // Union returns the union of t and u, where the result data for any common keys
-// is given by f(t's data, u's data) -- f need not be symmetric.  If f returns nil,
+// is given by f(t's data, u's data) -- f need not be symmetric.  If f returns false,
 // then the key and data are not added to the result.  If f is nil,
 // then wherever the sets overlap, the data from the larger set is used.
-func Union[K Comparable[K], D comparable](t, u *T[K, D], f func(x, y D) D) *T[K, D] {
+// Like Intersection, it is computed by splitting and joining subtrees.
+func Union[K any, D any, C Comparer[K]](t, u *Tree[K, D, C], f func(x, y D) (D, bool)) *Tree[K, D, C] {
 	if t.Size() == 0 {
 		return u
 	}
 	if u.Size() == 0 {
 		return t
 	}
-
-	if t.Size() >= u.Size() {
-		v := t.Copy()
-		for it := u.ToIter(); it.More(); {
-			k, e := it.Next()
-			d := t.Find(k)
-			if d == zero[D]() {
-				v.Insert(k, e)
-				continue
-			}
-			if f == nil {
-				continue
-			}
-			if c := f(d, e); c != d {
-				if c == zero[D]() {
-					v.Delete(k)
-				} else {
-					v.Insert(k, c)
-				}
-			}
-		}
-		return v
-	}
-
-	v := u.Copy()
-	for it := t.ToIter(); it.More(); {
-		k, d := it.Next()
-		e := u.Find(k)
-		if e == zero[D]() {
-			v.Insert(k, d)
-			continue
-		}
-		if f == nil {
-			continue
-		}
-		if c := f(d, e); c != d {
-			if c == zero[D]() {
-				v.Delete(k)
-			} else {
-				v.Insert(k, c)
-			}
-		}
+	if f == nil && t.Size() < u.Size() {
+		t, u = u, t
 	}
-	return v
+	r := union(t.root, u.root, f, t.cmp(), nil)
+	return t.withRoot(r)
 }
 
 // Difference returns the difference of t and u, except as
-// modified by f.  If f is nil, or returns nil, then the usual
-// difference results, however if it returns not-nil then
-// the entry is not removed and the new valye is used for the data.
-func Difference[K Comparable[K], D comparable](t, u *T[K, D], f func(x, y D) D) *T[K, D] {
+// modified by f.  If f is nil, or returns false, then the usual
+// difference results, however if it returns true then
+// the entry is not removed and the new value is used for the data.
+// Like Intersection, it is computed by splitting and joining subtrees.
+func Difference[K any, D any, C Comparer[K]](t, u *Tree[K, D, C], f func(x, y D) (D, bool)) *Tree[K, D, C] {
 	if t.Size() == 0 {
-		return &T[K, D]{}
+		return t.empty()
 	}
 	if u.Size() == 0 {
 		return t
 	}
-	v := t.Copy()
-	for it := t.ToIter(); it.More(); {
-		k, d := it.Next()
-		e := u.Find(k)
-		if e != zero[D]() {
-			if f == nil {
-				v.Delete(k)
-				continue
-			}
-			c := f(d, e)
-			if c == zero[D]() {
-				v.Delete(k)
-				continue
-			}
-			if c != d {
-				v.Insert(k, c)
-			}
-		}
+	r := difference(t.root, u.root, f, t.cmp(), nil)
+	return t.withRoot(r)
+}
+
+// SymmetricDifference returns the keys and data of t and u
+// whose keys appear in exactly one of them.
+// Like Intersection, it is computed by splitting and joining subtrees.
+func SymmetricDifference[K any, D any, C Comparer[K]](t, u *Tree[K, D, C]) *Tree[K, D, C] {
+	if t.Size() == 0 {
+		return u
+	}
+	if u.Size() == 0 {
+		return t
 	}
-	return v
+	r := symmetricDifference(t.root, u.root, t.cmp())
+	return t.withRoot(r)
 }
 
-func Equals[K Comparable[K], D comparable](t, u *T[K, D]) bool {
+func Equals[K any, D comparable, C Comparer[K]](t, u *Tree[K, D, C]) bool {
 	if t == u {
 		return true
 	}
 	if t.Size() != u.Size() {
 		return false
 	}
-	return equals(t.root, u.root)
+	for range Diff(t, u) {
+		return false
+	}
+	return true
 }
 
 const (
@@ -472,16 +545,18 @@ const (
 	ZERO_HEIGHT = 0
 )
 
-type node[K Comparable[K], D any] struct {
+type node[K any, D any] struct {
 	// Standard conventions hold for left = smaller, right = larger
 	left, right *node[K, D]
 	data        D
 	key         K
+	edit        *edit // if not nil, the Builder that may modify this node in place
+	size_       int32 // number of nodes in this subtree
 	height_     int8
 }
 
-func makeNode[K Comparable[K], D any](key K) *node[K, D] {
-	return &node[K, D]{key: key, height_: LEAF_HEIGHT}
+func makeNode[K any, D any](key K, e *edit) *node[K, D] {
+	return &node[K, D]{key: key, height_: LEAF_HEIGHT, size_: 1, edit: e}
 }
 
 func (n *node[K, D]) nilOrData() D {
@@ -504,6 +579,20 @@ func (n *node[K, D]) nilOrKeyAndData() (k K, d D) {
 	return
 }
 
+func (n *node[K, D]) dataAndOk() (D, bool) {
+	if n == nil {
+		return zero[D](), false
+	}
+	return n.data, true
+}
+
+func (n *node[K, D]) keyDataAndOk() (K, D, bool) {
+	if n == nil {
+		return zero[K](), zero[D](), false
+	}
+	return n.key, n.data, true
+}
+
 func (n *node[K, D]) height() int8 {
 	if n == nil {
 		return 0
@@ -511,6 +600,13 @@ func (n *node[K, D]) height() int8 {
 	return n.height_
 }
 
+func (n *node[K, D]) size() int {
+	if n == nil {
+		return 0
+	}
+	return int(n.size_)
+}
+
 func zero[T any]() T {
 	var z T
 	return z
@@ -527,13 +623,12 @@ func (n *node[K, D]) doAll2Flat(yield func(k K, d D) bool) {
 	if n == nil {
 		return
 	}
-	var stack [100]*node[K, D]
+	var stack nodeStack[K, D]
 	var top = 0
 
 	for n.left != nil {
-		stack[top] = n
+		top = stack.push(top, n)
 		n = n.left
-		top++
 	}
 
 	for {
@@ -544,9 +639,8 @@ func (n *node[K, D]) doAll2Flat(yield func(k K, d D) bool) {
 		if n.right != nil {
 			n = n.right
 			for n.left != nil {
-				stack[top] = n
+				top = stack.push(top, n)
 				n = n.left
-				top++
 			}
 		} else if top == 0 {
 			return
@@ -561,13 +655,12 @@ func (n *node[K, D]) doAll2FlatFilter(yield, filter func(k K, d D) bool) {
 	if n == nil {
 		return
 	}
-	var stack [100]*node[K, D]
+	var stack nodeStack[K, D]
 	var top = 0
 
 	for n.left != nil {
-		stack[top] = n
+		top = stack.push(top, n)
 		n = n.left
-		top++
 	}
 
 	for {
@@ -580,9 +673,8 @@ func (n *node[K, D]) doAll2FlatFilter(yield, filter func(k K, d D) bool) {
 		if n.right != nil {
 			n = n.right
 			for n.left != nil {
-				stack[top] = n
+				top = stack.push(top, n)
 				n = n.left
-				top++
 			}
 		} else if top == 0 {
 			return
@@ -593,63 +685,55 @@ func (n *node[K, D]) doAll2FlatFilter(yield, filter func(k K, d D) bool) {
 	}
 }
 
-func (n *node[K, D]) doAll(yield func(k K) bool) bool {
+// doAll2FlatBackward is the mirror image of doAll2Flat,
+// visiting keys from largest to smallest.
+func (n *node[K, D]) doAll2FlatBackward(yield func(k K, d D) bool) {
 	if n == nil {
-		return true
+		return
 	}
-	return n.left.doAll(yield) && yield(n.key) && n.right.doAll(yield)
-}
+	var stack nodeStack[K, D]
+	var top = 0
 
-func (n *node[K, D]) doAll_(yield func(d D) bool) bool {
-	if n == nil {
-		return true
+	for n.right != nil {
+		top = stack.push(top, n)
+		n = n.right
 	}
-	return n.left.doAll_(yield) && yield(n.data) && n.right.doAll_(yield)
-}
 
-func equals[K Comparable[K], D comparable](t, u *node[K, D]) bool {
-	if t == u {
-		return true
-	}
-	it, iu := t.iterator(), u.iterator()
-	for !it.isEmpty() && !iu.isEmpty() {
-		nt := it.next()
-		nu := iu.next()
-		if nt == nu {
-			continue
-		}
-		if nt.key.Compare(nu.key) != 0 {
-			return false
+	for {
+		// n.right == nil, stack[top-1] is parent.
+		if !yield(n.key, n.data) {
+			return
 		}
-		if nt.data != nu.data {
-			return false
+		if n.left != nil {
+			n = n.left
+			for n.right != nil {
+				top = stack.push(top, n)
+				n = n.right
+			}
+		} else if top == 0 {
+			return
+		} else {
+			top--
+			n = stack[top]
 		}
 	}
-	return it.isEmpty() == iu.isEmpty()
 }
 
-func (t *node[K, D]) equiv(u *node[K, D], eqv func(x, y D) bool) bool {
-	if t == u {
+func (n *node[K, D]) doAll(yield func(k K) bool) bool {
+	if n == nil {
 		return true
 	}
-	it, iu := t.iterator(), u.iterator()
-	for !it.isEmpty() && !iu.isEmpty() {
-		nt := it.next()
-		nu := iu.next()
-		if nt == nu {
-			continue
-		}
-		if nt.key.Compare(nu.key) != 0 {
-			return false
-		}
-		if !eqv(nt.data, nu.data) {
-			return false
-		}
+	return n.left.doAll(yield) && yield(n.key) && n.right.doAll(yield)
+}
+
+func (n *node[K, D]) doAll_(yield func(d D) bool) bool {
+	if n == nil {
+		return true
 	}
-	return it.isEmpty() == iu.isEmpty()
+	return n.left.doAll_(yield) && yield(n.data) && n.right.doAll_(yield)
 }
 
-type iterator[K Comparable[K], D any] struct {
+type iterator[K any, D any] struct {
 	parents []*node[K, D]
 }
 
@@ -710,9 +794,9 @@ func (t *node[K, D]) visitInOrder(f func(K, D)) {
 	}
 }
 
-func (t *node[K, D]) find(key K) *node[K, D] {
+func (t *node[K, D]) find(key K, compare func(a, b K) int) *node[K, D] {
 	for t != nil {
-		cmp := key.Compare(t.key)
+		cmp := compare(key, t.key)
 		if cmp < 0 {
 			t = t.left
 		} else if cmp > 0 {
@@ -744,88 +828,119 @@ func (t *node[K, D]) maximum() *node[K, D] {
 	return t
 }
 
-func (t *node[K, D]) glb(key K, allow_eq bool) *node[K, D] {
+func (t *node[K, D]) glb(key K, allow_eq bool, compare func(a, b K) int) *node[K, D] {
+	best, _ := t.glbStack(key, allow_eq, compare, nil, 0)
+	return best
+}
+
+func (t *node[K, D]) lub(key K, allow_eq bool, compare func(a, b K) int) *node[K, D] {
+	best, _ := t.lubStack(key, allow_eq, compare, nil, 0)
+	return best
+}
+
+// lubStack returns the least upper bound of key in t, or nil.  If stack
+// is not nil, it also pushes every upper bound it passes onto stack, so
+// that on return stack[top-1] is the lub and the remainder of the stack
+// holds its in-order successors that are not in its right subtree; the
+// new top is returned.
+func (t *node[K, D]) lubStack(key K, allow_eq bool, compare func(a, b K) int, stack *nodeStack[K, D], top int) (*node[K, D], int) {
 	var best *node[K, D] = nil
 	for t != nil {
-		if cmp := key.Compare(t.key); cmp <= 0 {
+		if cmp := compare(key, t.key); cmp >= 0 {
 			if allow_eq && cmp == 0 {
-				return t
+				if stack != nil {
+					top = stack.push(top, t)
+				}
+				return t, top
 			}
-			// t is too big, glb is to left.
-			t = t.left
+			// t is too small, lub is to right.
+			t = t.right
 		} else {
-			// t is a lower bound, record it and seek a better one.
+			// t is a upper bound, record it and seek a better one.
 			best = t
-			t = t.right
+			if stack != nil {
+				top = stack.push(top, t)
+			}
+			t = t.left
 		}
 	}
-	return best
+	return best, top
 }
 
-func (t *node[K, D]) lub(key K, allow_eq bool) *node[K, D] {
+// glbStack is the mirror image of lubStack.
+func (t *node[K, D]) glbStack(key K, allow_eq bool, compare func(a, b K) int, stack *nodeStack[K, D], top int) (*node[K, D], int) {
 	var best *node[K, D] = nil
 	for t != nil {
-		if cmp := key.Compare(t.key); cmp >= 0 {
+		if cmp := compare(key, t.key); cmp <= 0 {
 			if allow_eq && cmp == 0 {
-				return t
+				if stack != nil {
+					top = stack.push(top, t)
+				}
+				return t, top
 			}
-			// t is too small, lub is to right.
-			t = t.right
+			// t is too big, glb is to left.
+			t = t.left
 		} else {
-			// t is a upper bound, record it and seek a better one.
+			// t is a lower bound, record it and seek a better one.
 			best = t
-			t = t.left
+			if stack != nil {
+				top = stack.push(top, t)
+			}
+			t = t.right
 		}
 	}
-	return best
+	return best, top
 }
 
-func (t *node[K, D]) aInsert(x K) (newroot, newnode, oldnode *node[K, D]) {
+func (t *node[K, D]) aInsert(x K, compare func(a, b K) int, e *edit) (newroot, newnode, oldnode *node[K, D]) {
 	// oldnode default of nil is good, others should be assigned.
-	cmp := x.Compare(t.key)
+	cmp := compare(x, t.key)
 	if cmp == 0 {
 		oldnode = t
-		newt := *t
-		newnode = &newt
+		newnode = t.copyFor(e)
 		newroot = newnode
 		return
 	}
 	if cmp < 0 {
 		if t.left == nil {
-			t = t.copy()
-			n := makeNode[K, D](x)
+			t = t.copyFor(e)
+			n := makeNode[K, D](x, e)
 			t.left = n
 			newnode = n
 			newroot = t
 			t.height_ = 2 // was balanced w/ 0, sibling is height 0 or 1
+			t.size_++
 			return
 		}
 		var new_l *node[K, D]
-		new_l, newnode, oldnode = t.left.aInsert(x)
-		t = t.copy()
+		new_l, newnode, oldnode = t.left.aInsert(x, compare, e)
+		t = t.copyFor(e)
 		t.left = new_l
+		t.size_ = 1 + int32(new_l.size()+t.right.size())
 		if new_l.height() > 1+t.right.height() {
-			newroot = t.aLeftIsHigh(newnode)
+			newroot = t.aLeftIsHigh(newnode, e)
 		} else {
 			t.height_ = 1 + max(t.left.height(), t.right.height())
 			newroot = t
 		}
 	} else { // x > t.key
 		if t.right == nil {
-			t = t.copy()
-			n := makeNode[K, D](x)
+			t = t.copyFor(e)
+			n := makeNode[K, D](x, e)
 			t.right = n
 			newnode = n
 			newroot = t
 			t.height_ = 2 // was balanced w/ 0, sibling is height 0 or 1
+			t.size_++
 			return
 		}
 		var new_r *node[K, D]
-		new_r, newnode, oldnode = t.right.aInsert(x)
-		t = t.copy()
+		new_r, newnode, oldnode = t.right.aInsert(x, compare, e)
+		t = t.copyFor(e)
 		t.right = new_r
+		t.size_ = 1 + int32(new_r.size()+t.left.size())
 		if new_r.height() > 1+t.left.height() {
-			newroot = t.aRightIsHigh(newnode)
+			newroot = t.aRightIsHigh(newnode, e)
 		} else {
 			t.height_ = 1 + max(t.left.height(), t.right.height())
 			newroot = t
@@ -834,26 +949,30 @@ func (t *node[K, D]) aInsert(x K) (newroot, newnode, oldnode *node[K, D]) {
 	return
 }
 
-func (t *node[K, D]) aDelete(key K) (deleted, newSubTree *node[K, D]) {
+// aDelete removes key from t, returning the node that held it, or nil
+// if it was not found, and the new subtree.  Nodes owned by e are
+// modified in place, so the subtree may be changed even if it is the
+// same pointer; check the deleted node instead.
+func (t *node[K, D]) aDelete(key K, compare func(a, b K) int, e *edit) (deleted, newSubTree *node[K, D]) {
 	if t == nil {
 		return nil, nil
 	}
 
-	cmp := key.Compare(t.key)
+	cmp := compare(key, t.key)
 	if cmp < 0 {
 		oh := t.left.height()
-		d, tleft := t.left.aDelete(key)
-		if tleft == t.left {
+		d, tleft := t.left.aDelete(key, compare, e)
+		if d == nil {
 			return d, t
 		}
-		return d, t.copy().aRebalanceAfterLeftDeletion(oh, tleft)
+		return d, t.copyFor(e).aRebalanceAfterLeftDeletion(oh, tleft, e)
 	} else if cmp > 0 {
 		oh := t.right.height()
-		d, tright := t.right.aDelete(key)
-		if tright == t.right {
+		d, tright := t.right.aDelete(key, compare, e)
+		if d == nil {
 			return d, t
 		}
-		return d, t.copy().aRebalanceAfterRightDeletion(oh, tright)
+		return d, t.copyFor(e).aRebalanceAfterRightDeletion(oh, tright, e)
 	}
 
 	if t.height() == LEAF_HEIGHT {
@@ -864,22 +983,35 @@ func (t *node[K, D]) aDelete(key K) (deleted, newSubTree *node[K, D]) {
 	// then swapping contents
 	if t.left.height() > t.right.height() {
 		oh := t.left.height()
-		d, tleft := t.left.aDeleteMax()
-		r := t
-		t = t.copy()
-		t.data, t.key = d.data, d.key
-		return r, t.aRebalanceAfterLeftDeletion(oh, tleft)
+		d, tleft := t.left.aDeleteMax(e)
+		r, t := t.replaceContents(d, e)
+		return r, t.aRebalanceAfterLeftDeletion(oh, tleft, e)
 	}
 
 	oh := t.right.height()
-	d, tright := t.right.aDeleteMin()
-	r := t
-	t = t.copy()
-	t.data, t.key = d.data, d.key
-	return r, t.aRebalanceAfterRightDeletion(oh, tright)
+	d, tright := t.right.aDeleteMin(e)
+	r, t := t.replaceContents(d, e)
+	return r, t.aRebalanceAfterRightDeletion(oh, tright, e)
 }
 
-func (t *node[K, D]) aDeleteMin() (deleted, newSubTree *node[K, D]) {
+// replaceContents returns a node holding t's original key and data,
+// and t (or a copy of it, if not owned by e) holding d's key and data.
+// d must already be removed from the tree.
+func (t *node[K, D]) replaceContents(d *node[K, D], e *edit) (old, replaced *node[K, D]) {
+	dk, dd := d.key, d.data
+	old = t
+	t = t.copyFor(e)
+	if t == old {
+		// t is modified in place, so save its contents in d,
+		// which is no longer in the tree.
+		old = d.copyFor(e)
+		old.key, old.data = t.key, t.data
+	}
+	t.key, t.data = dk, dd
+	return old, t
+}
+
+func (t *node[K, D]) aDeleteMin(e *edit) (deleted, newSubTree *node[K, D]) {
 	if t == nil {
 		return nil, nil
 	}
@@ -887,14 +1019,11 @@ func (t *node[K, D]) aDeleteMin() (deleted, newSubTree *node[K, D]) {
 		return t, t.right
 	}
 	oh := t.left.height()
-	d, tleft := t.left.aDeleteMin()
-	if tleft == t.left {
-		return d, t
-	}
-	return d, t.copy().aRebalanceAfterLeftDeletion(oh, tleft)
+	d, tleft := t.left.aDeleteMin(e)
+	return d, t.copyFor(e).aRebalanceAfterLeftDeletion(oh, tleft, e)
 }
 
-func (t *node[K, D]) aDeleteMax() (deleted, newSubTree *node[K, D]) {
+func (t *node[K, D]) aDeleteMax(e *edit) (deleted, newSubTree *node[K, D]) {
 	if t == nil {
 		return nil, nil
 	}
@@ -904,15 +1033,13 @@ func (t *node[K, D]) aDeleteMax() (deleted, newSubTree *node[K, D]) {
 	}
 
 	oh := t.right.height()
-	d, tright := t.right.aDeleteMax()
-	if tright == t.right {
-		return d, t
-	}
-	return d, t.copy().aRebalanceAfterRightDeletion(oh, tright)
+	d, tright := t.right.aDeleteMax(e)
+	return d, t.copyFor(e).aRebalanceAfterRightDeletion(oh, tright, e)
 }
 
-func (t *node[K, D]) aRebalanceAfterLeftDeletion(oldLeftHeight int8, tleft *node[K, D]) *node[K, D] {
+func (t *node[K, D]) aRebalanceAfterLeftDeletion(oldLeftHeight int8, tleft *node[K, D], e *edit) *node[K, D] {
 	t.left = tleft
+	t.size_ = 1 + int32(tleft.size()+t.right.size())
 
 	if oldLeftHeight == tleft.height() || oldLeftHeight == t.right.height() {
 		// this node is still balanced and its height is unchanged
@@ -926,12 +1053,13 @@ func (t *node[K, D]) aRebalanceAfterLeftDeletion(oldLeftHeight int8, tleft *node
 	}
 
 	// left height fell by 1 and it was already less than right height
-	t.right = t.right.copy()
-	return t.aRightIsHigh(nil)
+	t.right = t.right.copyFor(e)
+	return t.aRightIsHigh(nil, e)
 }
 
-func (t *node[K, D]) aRebalanceAfterRightDeletion(oldRightHeight int8, tright *node[K, D]) *node[K, D] {
+func (t *node[K, D]) aRebalanceAfterRightDeletion(oldRightHeight int8, tright *node[K, D], e *edit) *node[K, D] {
 	t.right = tright
+	t.size_ = 1 + int32(tright.size()+t.left.size())
 
 	if oldRightHeight == tright.height() || oldRightHeight == t.left.height() {
 		// this node is still balanced and its height is unchanged
@@ -945,18 +1073,18 @@ func (t *node[K, D]) aRebalanceAfterRightDeletion(oldRightHeight int8, tright *n
 	}
 
 	// right height fell by 1 and it was already less than left height
-	t.left = t.left.copy()
-	return t.aLeftIsHigh(nil)
+	t.left = t.left.copyFor(e)
+	return t.aLeftIsHigh(nil, e)
 }
 
 // aRightIsHigh does rotations necessary to fix a high right child
 // assume that t and t.right are already fresh copies.
-func (t *node[K, D]) aRightIsHigh(newnode *node[K, D]) *node[K, D] {
+func (t *node[K, D]) aRightIsHigh(newnode *node[K, D], e *edit) *node[K, D] {
 	right := t.right
 	if right.right.height() < right.left.height() {
 		// double rotation
 		if newnode != right.left {
-			right.left = right.left.copy()
+			right.left = right.left.copyFor(e)
 		}
 		t.right = right.leftToRoot()
 	}
@@ -966,12 +1094,12 @@ func (t *node[K, D]) aRightIsHigh(newnode *node[K, D]) *node[K, D] {
 
 // aLeftIsHigh does rotations necessary to fix a high left child
 // assume that t and t.left are already fresh copies.
-func (t *node[K, D]) aLeftIsHigh(newnode *node[K, D]) *node[K, D] {
+func (t *node[K, D]) aLeftIsHigh(newnode *node[K, D], e *edit) *node[K, D] {
 	left := t.left
 	if left.left.height() < left.right.height() {
 		// double rotation
 		if newnode != left.right {
-			left.right = left.right.copy()
+			left.right = left.right.copyFor(e)
 		}
 		t.left = left.rightToRoot()
 	}
@@ -997,7 +1125,9 @@ func (t *node[K, D]) rightToRoot() *node[K, D] {
 	// parent's child ptr fixed in caller
 	t.right = rl
 	t.height_ = 1 + max(rl.height(), t.left.height())
+	t.size_ = 1 + int32(rl.size()+t.left.size())
 	right.height_ = 1 + max(t.height(), right.right.height())
+	right.size_ = 1 + int32(t.size()+right.right.size())
 	return right
 }
 
@@ -1019,7 +1149,9 @@ func (t *node[K, D]) leftToRoot() *node[K, D] {
 	// parent's child ptr fixed in caller
 	t.left = lr
 	t.height_ = 1 + max(lr.height(), t.right.height())
+	t.size_ = 1 + int32(lr.size()+t.right.size())
 	left.height_ = 1 + max(t.height(), left.left.height())
+	left.size_ = 1 + int32(t.size()+left.left.size())
 	return left
 }
 
@@ -1027,3 +1159,14 @@ func (t *node[K, D]) copy() *node[K, D] {
 	u := *t
 	return &u
 }
+
+// copyFor returns t itself if it is owned by e, otherwise
+// a copy of t that is owned by e.
+func (t *node[K, D]) copyFor(e *edit) *node[K, D] {
+	if e != nil && t.edit == e {
+		return t
+	}
+	u := *t
+	u.edit = e
+	return &u
+}
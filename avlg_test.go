@@ -0,0 +1,355 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+type Int int
+
+func (x Int) Compare(y Int) int {
+	if x < y {
+		return -1
+	}
+	if x > y {
+		return 1
+	}
+	return 0
+}
+
+// makeTree returns a tree containing keys lo..hi-1 (by step), inserted
+// in a scrambled order, with data equal to 10 * key.
+func makeTree(lo, hi, step int) *T[Int, int] {
+	t := &T[Int, int]{}
+	var keys []int
+	for i := lo; i < hi; i += step {
+		keys = append(keys, i)
+	}
+	for i := range keys {
+		j := (i * 7919) % len(keys)
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+	for _, k := range keys {
+		t.Insert(Int(k), 10*k)
+	}
+	return t
+}
+
+func TestAllKeysValuesBackward(t *testing.T) {
+	tr := makeTree(0, 1000, 1)
+	var want []Int
+	for i := 0; i < 1000; i++ {
+		want = append(want, Int(i))
+	}
+	if got := slices.Collect(tr.Keys()); !slices.Equal(got, want) {
+		t.Errorf("Keys() = %v, want %v", got, want)
+	}
+	for k, d := range tr.All() {
+		if d != 10*int(k) {
+			t.Errorf("All() yielded %d:%d", k, d)
+		}
+	}
+	vals := slices.Collect(tr.Values())
+	if len(vals) != 1000 || !slices.IsSorted(vals) {
+		t.Errorf("Values() returned %d unsorted values", len(vals))
+	}
+	var back []Int
+	for k := range tr.Backward() {
+		back = append(back, k)
+	}
+	slices.Reverse(back)
+	if !slices.Equal(back, want) {
+		t.Errorf("Backward() = %v, want reverse of %v", back, want)
+	}
+	m := map[Int]int{}
+	maps.Insert(m, tr.All())
+	if len(m) != tr.Size() {
+		t.Errorf("maps.Insert(All()) has %d entries, want %d", len(m), tr.Size())
+	}
+
+	// Early exit.
+	n := 0
+	for range tr.Backward() {
+		n++
+		if n == 5 {
+			break
+		}
+	}
+	if n != 5 {
+		t.Errorf("Backward() early exit count = %d, want 5", n)
+	}
+
+	empty := &T[Int, int]{}
+	if got := slices.Collect(empty.Keys()); len(got) != 0 {
+		t.Errorf("empty Keys() = %v", got)
+	}
+}
+
+func TestRange(t *testing.T) {
+	tr := makeTree(0, 200, 2) // even keys 0..198
+	bounds := func(k int) []Bound[Int] {
+		return []Bound[Int]{Unbounded[Int](), Inclusive(Int(k)), Exclusive(Int(k))}
+	}
+	for lo := -3; lo < 203; lo += 7 {
+		for hi := lo - 2; hi < 205; hi += 5 {
+			for _, l := range bounds(lo) {
+				for _, h := range bounds(hi) {
+					var want []Int
+					for k := range tr.Keys() {
+						if aboveLo(l, k, Int.Compare) && belowHi(h, k, Int.Compare) {
+							want = append(want, k)
+						}
+					}
+					var got []Int
+					for k, d := range tr.Range(l, h) {
+						if d != 10*int(k) {
+							t.Fatalf("Range yielded %d:%d", k, d)
+						}
+						got = append(got, k)
+					}
+					if !slices.Equal(got, want) {
+						t.Fatalf("Range(%v, %v) = %v, want %v", l, h, got, want)
+					}
+					got = got[:0]
+					for k := range tr.RangeBackward(l, h) {
+						got = append(got, k)
+					}
+					slices.Reverse(got)
+					if !slices.Equal(got, want) {
+						t.Fatalf("RangeBackward(%v, %v) = %v, want %v", l, h, got, want)
+					}
+				}
+			}
+		}
+	}
+	var got []Int
+	for k := range tr.From(195) {
+		got = append(got, k)
+	}
+	if !slices.Equal(got, []Int{196, 198}) {
+		t.Errorf("From(195) = %v", got)
+	}
+	got = got[:0]
+	for k := range tr.Until(6) {
+		got = append(got, k)
+	}
+	if !slices.Equal(got, []Int{0, 2, 4}) {
+		t.Errorf("Until(6) = %v", got)
+	}
+}
+
+func TestPresence(t *testing.T) {
+	tr := &T[Int, int]{}
+	tr.Insert(1, 0)
+	tr.Insert(3, 30)
+	if d, ok := tr.Get(1); !ok || d != 0 {
+		t.Errorf("Get(1) = %d, %v; want 0, true", d, ok)
+	}
+	if _, ok := tr.Get(2); ok || tr.Contains(2) || !tr.Contains(1) {
+		t.Errorf("Get/Contains reports wrong presence")
+	}
+	if k, _, ok := tr.GlbOk(1); ok {
+		t.Errorf("GlbOk(1) = %d, true; want false", k)
+	}
+	if k, d, ok := tr.LubEqOk(2); !ok || k != 3 || d != 30 {
+		t.Errorf("LubEqOk(2) = %d, %d, %v", k, d, ok)
+	}
+	if d, ok := tr.DeleteOk(1); !ok || d != 0 {
+		t.Errorf("DeleteOk(1) = %d, %v", d, ok)
+	}
+	if _, ok := tr.DeleteOk(1); ok {
+		t.Errorf("second DeleteOk(1) succeeded")
+	}
+	if _, _, ok := tr.DeleteMaxOk(); !ok {
+		t.Errorf("DeleteMaxOk failed")
+	}
+	if _, _, ok := tr.DeleteMinOk(); ok {
+		t.Errorf("DeleteMinOk on empty tree succeeded")
+	}
+	if _, _, ok := tr.MinOk(); ok {
+		t.Errorf("MinOk on empty tree succeeded")
+	}
+}
+
+func TestSetOpsZeroData(t *testing.T) {
+	a, b := &T[Int, []int]{}, &T[Int, []int]{}
+	for i := 0; i < 10; i++ {
+		a.Insert(Int(i), nil) // zero data must still count as present
+	}
+	for i := 5; i < 20; i++ {
+		b.Insert(Int(i), []int{i})
+	}
+	if u := Union(a, b, nil); u.Size() != 20 {
+		t.Errorf("Union size = %d, want 20", u.Size())
+	}
+	if i := Intersection(a, b, nil); i.Size() != 5 {
+		t.Errorf("Intersection size = %d, want 5", i.Size())
+	}
+	if d := Difference(a, b, nil); d.Size() != 5 || d.Contains(5) {
+		t.Errorf("Difference = %v", d)
+	}
+	if d := Difference(b, a, nil); d.Size() != 10 || d.Contains(9) {
+		t.Errorf("Difference = %v", d)
+	}
+	odd := func(x, y []int) ([]int, bool) { return y, y[0]%2 == 1 }
+	if i := Intersection(a, b, odd); i.Size() != 3 {
+		t.Errorf("Intersection with f = %v", i)
+	}
+}
+
+// checkSizes verifies the subtree sizes cached in n, returning the size.
+func checkSizes[K any, D any](t *testing.T, n *node[K, D]) int {
+	if n == nil {
+		return 0
+	}
+	s := 1 + checkSizes(t, n.left) + checkSizes(t, n.right)
+	if s != n.size() {
+		t.Fatalf("node %v has size %d, want %d", n.key, n.size(), s)
+	}
+	return s
+}
+
+func TestOrderStatistics(t *testing.T) {
+	tr := makeTree(0, 300, 3) // 0, 3, ..., 297
+	checkSizes(t, tr.root)
+	for i := 0; i < 100; i++ {
+		k, d := tr.At(i)
+		if int(k) != 3*i || d != 30*i {
+			t.Fatalf("At(%d) = %d, %d", i, k, d)
+		}
+		if r := tr.Rank(k); r != i {
+			t.Fatalf("Rank(%d) = %d, want %d", k, r, i)
+		}
+		if r := tr.Rank(k + 1); r != i+1 {
+			t.Fatalf("Rank(%d) = %d, want %d", k+1, r, i+1)
+		}
+		var got []Int
+		for k := range tr.Skip(i) {
+			got = append(got, k)
+		}
+		if len(got) != 100-i || int(got[0]) != 3*i {
+			t.Fatalf("Skip(%d) yielded %d keys starting at %d", i, len(got), got[0])
+		}
+	}
+	if _, _, ok := tr.Select(100); ok {
+		t.Errorf("Select(100) succeeded")
+	}
+	if n := tr.CountRange(Inclusive[Int](3), Exclusive[Int](30)); n != 9 {
+		t.Errorf("CountRange[3, 30) = %d, want 9", n)
+	}
+	if n := tr.CountRange(Exclusive[Int](3), Inclusive[Int](30)); n != 9 {
+		t.Errorf("CountRange(3, 30] = %d, want 9", n)
+	}
+	if n := tr.CountRange(Exclusive[Int](30), Exclusive[Int](3)); n != 0 {
+		t.Errorf("CountRange(30, 3) = %d, want 0", n)
+	}
+
+	// Sizes must survive deletions and their rebalancing.
+	for i := 0; i < 300; i += 7 {
+		tr.Delete(Int(i))
+		tr.DeleteMin()
+		checkSizes(t, tr.root)
+		if tr.root.size() != tr.Size() {
+			t.Fatalf("root size %d, tree size %d", tr.root.size(), tr.Size())
+		}
+	}
+}
+
+func TestFromSortedAndCollect(t *testing.T) {
+	ref := makeTree(0, 1000, 1)
+	tr, err := FromSorted(ref.All())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSizes(t, tr.root)
+	if tr.String() != ref.String() || tr.Size() != 1000 || tr.root.height() != bits(1000) {
+		t.Errorf("FromSorted = %v, height %d", tr, tr.root.height())
+	}
+	if _, err := FromSorted(ref.Backward()); err == nil {
+		t.Errorf("FromSorted of descending input did not fail")
+	}
+
+	c := Collect(func(yield func(Int, int) bool) {
+		for i := 999; i >= 0; i-- {
+			if !yield(Int(i), -1) || !yield(Int(i), 10*i) {
+				return
+			}
+		}
+	})
+	checkSizes(t, c.root)
+	if c.String() != ref.String() {
+		t.Errorf("Collect = %v", c)
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		FromSorted(ref.All())
+	})
+	// n nodes, plus the tree and the growth of the pair slice.
+	if allocs > 1000+20 && !debugValidate {
+		t.Errorf("FromSorted allocated %v times for 1000 elements", allocs)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	base := makeTree(0, 500, 2)
+	baseString := base.String()
+	ref := base.Copy()
+	b := base.Transient()
+	var frozen []*T[Int, int]
+	var frozenStrings []string
+	x := 1
+	for i := 0; i < 5000; i++ {
+		x = (x*1103515245 + 12345) & 0x7fffffff
+		k := Int(x % 600)
+		switch x % 5 {
+		case 0, 1:
+			if got, want := b.Insert(k, i), ref.Insert(k, i); got != want {
+				t.Fatalf("Insert(%d) = %d, want %d", k, got, want)
+			}
+		case 2, 3:
+			if got, want := b.Delete(k), ref.Delete(k); got != want {
+				t.Fatalf("Delete(%d) = %d, want %d", k, got, want)
+			}
+		case 4:
+			k1, d1 := b.DeleteMin()
+			k2, d2 := ref.DeleteMin()
+			if k1 != k2 || d1 != d2 {
+				t.Fatalf("DeleteMin = %d:%d, want %d:%d", k1, d1, k2, d2)
+			}
+			k1, d1 = b.DeleteMax()
+			k2, d2 = ref.DeleteMax()
+			if k1 != k2 || d1 != d2 {
+				t.Fatalf("DeleteMax = %d:%d, want %d:%d", k1, d1, k2, d2)
+			}
+		}
+		if i%500 == 0 {
+			p := b.Persistent()
+			checkSizes(t, p.root)
+			if p.String() != ref.String() || p.Size() != ref.Size() {
+				t.Fatalf("Builder diverged at step %d", i)
+			}
+			frozen = append(frozen, p)
+			frozenStrings = append(frozenStrings, p.String())
+		}
+	}
+	if base.String() != baseString {
+		t.Errorf("Builder modified the tree it started from")
+	}
+	for i, p := range frozen {
+		if p.String() != frozenStrings[i] {
+			t.Errorf("Builder modified a tree returned by Persistent")
+		}
+	}
+
+	// Repeated edits to an owned path should not allocate.
+	b = makeTree(0, 1000, 1).Transient()
+	b.Insert(500, 0)
+	if allocs := testing.AllocsPerRun(100, func() { b.Insert(500, 1) }); allocs != 0 && !debugValidate {
+		t.Errorf("Builder.Insert of an owned key allocated %v times", allocs)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+type pair[K, D any] struct {
+	key  K
+	data D
+}
+
+// FromSorted returns a tree containing the key and data pairs of seq,
+// which must be in strictly increasing key order; if they are not,
+// FromSorted returns an error.  The tree is built in O(n) time and is
+// perfectly balanced, and exactly one node is allocated per element.
+func FromSorted[K Comparable[K], D any](seq iter.Seq2[K, D]) (*T[K, D], error) {
+	return fromSorted(&T[K, D]{}, seq)
+}
+
+// FromSortedOrdered is like FromSorted, but returns an Ordered tree.
+func FromSortedOrdered[K cmp.Ordered, D any](seq iter.Seq2[K, D]) (*Ordered[K, D], error) {
+	return fromSorted(&Ordered[K, D]{}, seq)
+}
+
+// FromSortedFunc is like FromSorted, but returns a Func tree ordered by cmp.
+func FromSortedFunc[K any, D any](seq iter.Seq2[K, D], cmp func(a, b K) int) (*Func[K, D], error) {
+	return fromSorted(NewFunc[K, D](cmp), seq)
+}
+
+// Collect returns a tree containing the key and data pairs of seq,
+// which may be in any order.  If a key appears more than once, the
+// last data for it wins, as if the pairs were inserted in order.
+// The pairs are sorted and then built into a tree as by FromSorted.
+func Collect[K Comparable[K], D any](seq iter.Seq2[K, D]) *T[K, D] {
+	return collect(&T[K, D]{}, seq)
+}
+
+// CollectOrdered is like Collect, but returns an Ordered tree.
+func CollectOrdered[K cmp.Ordered, D any](seq iter.Seq2[K, D]) *Ordered[K, D] {
+	return collect(&Ordered[K, D]{}, seq)
+}
+
+// CollectFunc is like Collect, but returns a Func tree ordered by cmp.
+func CollectFunc[K any, D any](seq iter.Seq2[K, D], cmp func(a, b K) int) *Func[K, D] {
+	return collect(NewFunc[K, D](cmp), seq)
+}
+
+// fromSorted fills the empty tree t from seq, as described for FromSorted.
+func fromSorted[K any, D any, C Comparer[K]](t *Tree[K, D, C], seq iter.Seq2[K, D]) (*Tree[K, D, C], error) {
+	compare := t.cmp()
+	var pairs []pair[K, D]
+	for k, d := range seq {
+		if l := len(pairs); l > 0 && compare(pairs[l-1].key, k) >= 0 {
+			return nil, fmt.Errorf("FromSorted: key %v at position %d does not follow %v", k, l, pairs[l-1].key)
+		}
+		pairs = append(pairs, pair[K, D]{k, d})
+	}
+	t.root, t.size = buildBalanced(pairs), len(pairs)
+	return t.check(), nil
+}
+
+// collect fills the empty tree t from seq, as described for Collect.
+func collect[K any, D any, C Comparer[K]](t *Tree[K, D, C], seq iter.Seq2[K, D]) *Tree[K, D, C] {
+	compare := t.cmp()
+	var pairs []pair[K, D]
+	for k, d := range seq {
+		pairs = append(pairs, pair[K, D]{k, d})
+	}
+	slices.SortStableFunc(pairs, func(a, b pair[K, D]) int {
+		return compare(a.key, b.key)
+	})
+	// Deduplicate; stability means the last of a run of equal keys
+	// is the last one seen.
+	j := 0
+	for i := range pairs {
+		if i+1 < len(pairs) && compare(pairs[i].key, pairs[i+1].key) == 0 {
+			continue
+		}
+		pairs[j] = pairs[i]
+		j++
+	}
+	t.root, t.size = buildBalanced(pairs[:j]), j
+	return t.check()
+}
+
+// buildBalanced returns a perfectly balanced tree containing pairs,
+// which must be sorted and free of duplicates.
+func buildBalanced[K any, D any](pairs []pair[K, D]) *node[K, D] {
+	if len(pairs) == 0 {
+		return nil
+	}
+	mid := len(pairs) / 2
+	n := &node[K, D]{key: pairs[mid].key, data: pairs[mid].data}
+	n.left = buildBalanced(pairs[:mid])
+	n.right = buildBalanced(pairs[mid+1:])
+	n.fix()
+	return n
+}
@@ -0,0 +1,40 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import "cmp"
+
+// A Comparer orders the keys of a Tree.  Its Compare method returns
+// a negative number, zero, or a positive number as a is less than,
+// equal to, or greater than b.  The Comparers here have no state
+// beyond, for FuncCompare, the function itself, so a tree operation
+// takes the method value t.order.Compare once and passes it down to
+// the nodes without allocating.
+type Comparer[K any] interface {
+	Compare(a, b K) int
+}
+
+// MethodCompare orders keys by their Compare method.
+type MethodCompare[K Comparable[K]] struct{}
+
+func (MethodCompare[K]) Compare(a, b K) int {
+	return a.Compare(b)
+}
+
+// OrderedCompare orders keys by cmp.Compare.
+type OrderedCompare[K cmp.Ordered] struct{}
+
+func (OrderedCompare[K]) Compare(a, b K) int {
+	return cmp.Compare(a, b)
+}
+
+// FuncCompare orders keys by a function supplied at run time.
+type FuncCompare[K any] struct {
+	cmp func(a, b K) int
+}
+
+func (c FuncCompare[K]) Compare(a, b K) int {
+	return c.cmp(a, b)
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOrderedAndFunc(t *testing.T) {
+	o := &Ordered[string, int]{}
+	f := NewFunc[string, int](func(a, b string) int {
+		// reverse, case-insensitive order
+		return strings.Compare(strings.ToLower(b), strings.ToLower(a))
+	})
+	for i, s := range []string{"emu", "Ant", "cat", "Dog", "bat"} {
+		o.Insert(s, i)
+		f.Insert(s, i)
+	}
+	if got, want := slices.Collect(o.Keys()), []string{"Ant", "Dog", "bat", "cat", "emu"}; !slices.Equal(got, want) {
+		t.Errorf("Ordered keys = %v, want %v", got, want)
+	}
+	if got, want := slices.Collect(f.Keys()), []string{"emu", "Dog", "cat", "bat", "Ant"}; !slices.Equal(got, want) {
+		t.Errorf("Func keys = %v, want %v", got, want)
+	}
+	if d, ok := f.Get("DOG"); !ok || d != 3 {
+		t.Errorf("Func Get(DOG) = %d, %v", d, ok)
+	}
+
+	// Derived trees keep the ordering of their inputs.
+	g := f.Copy()
+	g.Insert("fox", 5)
+	if u := Union(f, g, nil); u.Size() != 6 || !u.Contains("FOX") {
+		t.Errorf("Func Union = %v", u)
+	}
+	less, _, _, _ := f.Split("cat")
+	if got := slices.Collect(less.Keys()); !slices.Equal(got, []string{"emu", "Dog"}) {
+		t.Errorf("Func Split less = %v", got)
+	}
+	c, err := FromSortedFunc(f.All(), func(a, b string) int {
+		return strings.Compare(strings.ToLower(b), strings.ToLower(a))
+	})
+	if err != nil || c.String() != f.String() {
+		t.Errorf("FromSortedFunc = %v, %v", c, err)
+	}
+	if _, err := FromSortedOrdered(f.All()); err == nil {
+		t.Errorf("FromSortedOrdered of reverse-ordered input did not fail")
+	}
+}
+
+// TestLookupAllocs checks that looking up a key allocates nothing,
+// whichever way the tree orders its keys.
+func TestLookupAllocs(t *testing.T) {
+	if debugValidate {
+		t.Skip("validation allocates")
+	}
+	checkLookupAllocs(t, "T", &T[Int, int]{})
+	checkLookupAllocs(t, "Ordered", &Ordered[Int, int]{})
+	checkLookupAllocs(t, "Func", NewFunc[Int, int](cmp.Compare[Int]))
+}
+
+func checkLookupAllocs[C Comparer[Int]](t *testing.T, name string, tr *Tree[Int, int, C]) {
+	t.Helper()
+	for i := range 100 {
+		tr.Insert(Int(i), i)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { tr.Find(37) }); allocs != 0 {
+		t.Errorf("%s Find allocated %v times", name, allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { tr.Get(37) }); allocs != 0 {
+		t.Errorf("%s Get allocated %v times", name, allocs)
+	}
+}
+
+const dispatchSize = 1 << 12
+
+func benchmarkFind[C Comparer[Int]](b *testing.B, tr *Tree[Int, int, C]) {
+	for i := 0; i < dispatchSize; i++ {
+		tr.Insert(Int(i), i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Find(Int(i & (dispatchSize - 1)))
+	}
+}
+
+func benchmarkInsert[C Comparer[Int]](b *testing.B, tr *Tree[Int, int, C]) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tr.Insert(Int(i&(dispatchSize-1)), i)
+	}
+}
+
+// The three dispatch styles, compared on the same key type.
+
+func BenchmarkFindMethod(b *testing.B)  { benchmarkFind(b, &T[Int, int]{}) }
+func BenchmarkFindOrdered(b *testing.B) { benchmarkFind(b, &Ordered[Int, int]{}) }
+func BenchmarkFindFunc(b *testing.B)    { benchmarkFind(b, NewFunc[Int, int](cmp.Compare[Int])) }
+
+func BenchmarkInsertMethod(b *testing.B)  { benchmarkInsert(b, &T[Int, int]{}) }
+func BenchmarkInsertOrdered(b *testing.B) { benchmarkInsert(b, &Ordered[Int, int]{}) }
+func BenchmarkInsertFunc(b *testing.B)    { benchmarkInsert(b, NewFunc[Int, int](cmp.Compare[Int])) }
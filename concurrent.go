@@ -0,0 +1,142 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// A Concurrent holds a tree that may be read and updated by many
+// goroutines at once.  Readers never wait: each takes a Snapshot, an
+// immutable version of the tree that later updates do not change.
+// Writers build a new version from the current one and install it
+// with a compare-and-swap, retrying if another writer got there first.
+//
+// Because an update may be retried, the functions passed to Update
+// and UpdateBatched may be called more than once, each time with the
+// then-current version, and must not have other side effects.
+type Concurrent[K any, D any, C Comparer[K]] struct {
+	current atomic.Pointer[Snapshot[K, D, C]]
+
+	mu      sync.Mutex // protects pending and leading
+	pending []*batchedUpdate[K, D, C]
+	leading bool // a goroutine is applying batches
+}
+
+// A batchedUpdate is a call to UpdateBatched.  Its caller is woken,
+// by a send on wake, either to take the lead or when the update has
+// been applied; the fields after wake tell it which, and how it went.
+type batchedUpdate[K any, D any, C Comparer[K]] struct {
+	f        func(*Tree[K, D, C]) *Tree[K, D, C]
+	wake     chan struct{}
+	lead     bool // the caller now leads
+	retry    bool // another update in the batch panicked
+	panicked any  // the value f panicked with
+}
+
+// NewConcurrent returns a Concurrent whose first version, number 0, is t.
+// Later changes to t do not affect it.
+func NewConcurrent[K any, D any, C Comparer[K]](t *Tree[K, D, C]) *Concurrent[K, D, C] {
+	c := &Concurrent[K, D, C]{}
+	c.current.Store(&Snapshot[K, D, C]{t: *t})
+	return c
+}
+
+// Snapshot returns the current version of the tree.  Its Version
+// counts the updates before it.
+func (c *Concurrent[K, D, C]) Snapshot() *Snapshot[K, D, C] {
+	return c.current.Load()
+}
+
+// Update replaces the current version t by f(t), where f is given its
+// own copy of t to edit, and returns the number of the new version.
+func (c *Concurrent[K, D, C]) Update(f func(*Tree[K, D, C]) *Tree[K, D, C]) int {
+	for {
+		old := c.current.Load()
+		t := f(old.t.Copy())
+		next := &Snapshot[K, D, C]{t: *t, version: old.version + 1}
+		if c.current.CompareAndSwap(old, next) {
+			return next.version
+		}
+	}
+}
+
+// UpdateBatched is like Update, but combines concurrent calls into
+// batches, applying each batch's functions in turn and installing the
+// result with one compare-and-swap.  Under heavy contention this
+// avoids most retries.  When UpdateBatched returns, the update made by
+// f has been installed.
+//
+// One caller at a time leads, applying the batch of updates pending
+// when it took the lead and then handing the lead to a waiting caller,
+// so that no caller applies batches indefinitely.  If f panics, its
+// batch is not installed; UpdateBatched panics with the same value in
+// f's caller, and the other callers in the batch try again.
+func (c *Concurrent[K, D, C]) UpdateBatched(f func(*Tree[K, D, C]) *Tree[K, D, C]) {
+	u := &batchedUpdate[K, D, C]{f: f, wake: make(chan struct{}, 1)}
+	for {
+		c.mu.Lock()
+		c.pending = append(c.pending, u)
+		lead := !c.leading
+		c.leading = true
+		c.mu.Unlock()
+		if !lead {
+			<-u.wake
+		}
+		if lead || u.lead {
+			u.lead = false
+			c.applyBatch()
+			<-u.wake
+		}
+		if u.panicked != nil {
+			panic(u.panicked)
+		}
+		if !u.retry {
+			return
+		}
+		u.retry = false
+	}
+}
+
+// applyBatch applies the pending updates, tells each caller the
+// outcome, and passes the lead to the next caller waiting, if any.
+func (c *Concurrent[K, D, C]) applyBatch() {
+	c.mu.Lock()
+	batch := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	var current *batchedUpdate[K, D, C] // the update being applied
+	defer func() {
+		r := recover()
+		for _, u := range batch {
+			switch {
+			case r == nil:
+			case u == current:
+				u.panicked = r
+			default:
+				u.retry = true
+			}
+			u.wake <- struct{}{}
+		}
+		c.mu.Lock()
+		if len(c.pending) > 0 {
+			next := c.pending[0]
+			next.lead = true
+			next.wake <- struct{}{}
+		} else {
+			c.leading = false
+		}
+		c.mu.Unlock()
+	}()
+	c.Update(func(t *Tree[K, D, C]) *Tree[K, D, C] {
+		for _, u := range batch {
+			current = u
+			t = u.f(t)
+		}
+		return t
+	})
+}
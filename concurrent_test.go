@@ -0,0 +1,158 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConcurrent(t *testing.T) {
+	for _, batched := range []bool{false, true} {
+		c := NewConcurrent(&T[Int, int]{})
+		const writers, perWriter = 8, 300
+		var wg, readers sync.WaitGroup
+		stop := make(chan struct{})
+		for range runtime.GOMAXPROCS(0) {
+			readers.Add(1)
+			go func() {
+				defer readers.Done()
+				last := -1
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					// Every snapshot is a complete, valid version,
+					// and versions only move forward.
+					s := c.Snapshot()
+					tr := s.Tree()
+					if err := tr.Validate(); err != nil {
+						t.Error(err)
+						return
+					}
+					// Each update adds one key; a batch may add several.
+					if n := tr.Size(); s.Version() < last || n < s.Version() || !batched && n != s.Version() {
+						t.Errorf("snapshot version %d (after %d) has %d keys", s.Version(), last, tr.Size())
+						return
+					}
+					last = s.Version()
+				}
+			}()
+		}
+		for w := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range perWriter {
+					k := Int(w*perWriter + i)
+					insert := func(t *T[Int, int]) *T[Int, int] {
+						t.Insert(k, int(k))
+						return t
+					}
+					if batched {
+						c.UpdateBatched(insert)
+						if !c.Snapshot().Contains(k) {
+							t.Errorf("key %d missing after UpdateBatched returned", k)
+						}
+					} else {
+						c.Update(insert)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		close(stop)
+		readers.Wait()
+
+		s := c.Snapshot()
+		if s.Size() != writers*perWriter {
+			t.Errorf("batched=%v: final size %d, want %d", batched, s.Size(), writers*perWriter)
+		}
+		if !batched && s.Version() != writers*perWriter {
+			t.Errorf("final version %d, want one per update", s.Version())
+		}
+		t.Logf("batched=%v: %d updates in %d versions", batched, s.Size(), s.Version())
+	}
+}
+
+func benchmarkConcurrent(b *testing.B, update func(c *Concurrent[Int, int, MethodCompare[Int]], f func(*T[Int, int]) *T[Int, int])) {
+	c := NewConcurrent(makeTree(0, 1<<12, 1))
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			k := Int(i & (1<<12 - 1))
+			i += 7
+			update(c, func(t *T[Int, int]) *T[Int, int] {
+				t.Insert(k, i)
+				return t
+			})
+		}
+	})
+}
+
+func BenchmarkConcurrentUpdate(b *testing.B) {
+	benchmarkConcurrent(b, func(c *Concurrent[Int, int, MethodCompare[Int]], f func(*T[Int, int]) *T[Int, int]) {
+		c.Update(f)
+	})
+}
+
+func BenchmarkConcurrentUpdateBatched(b *testing.B) {
+	benchmarkConcurrent(b, (*Concurrent[Int, int, MethodCompare[Int]]).UpdateBatched)
+}
+
+// TestUpdateBatchedPanic checks that a panicking update reaches its own
+// caller, and that the other updates are still made.
+func TestUpdateBatchedPanic(t *testing.T) {
+	c := NewConcurrent(&T[Int, int]{})
+	const writers, perWriter = 8, 100
+	var wg sync.WaitGroup
+	var panics atomic.Int32
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				k := Int(w*perWriter + i)
+				func() {
+					defer func() {
+						if r := recover(); r != nil {
+							if r != k {
+								t.Errorf("update of %d panicked with %v", k, r)
+							}
+							panics.Add(1)
+						}
+					}()
+					c.UpdateBatched(func(t *T[Int, int]) *T[Int, int] {
+						if k%10 == 0 {
+							panic(k)
+						}
+						t.Insert(k, int(k))
+						return t
+					})
+				}()
+			}
+		}()
+	}
+	wg.Wait()
+	s := c.Snapshot()
+	if p := panics.Load(); p != writers*perWriter/10 {
+		t.Errorf("%d updates panicked, want %d", p, writers*perWriter/10)
+	}
+	if want := writers * perWriter * 9 / 10; s.Size() != want {
+		t.Errorf("final size %d, want %d", s.Size(), want)
+	}
+	// Batching still works after the panics.
+	c.UpdateBatched(func(t *T[Int, int]) *T[Int, int] {
+		t.Insert(-1, -1)
+		return t
+	})
+	if !c.Snapshot().Contains(-1) {
+		t.Errorf("update after panics was not installed")
+	}
+}
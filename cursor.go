@@ -0,0 +1,138 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+// A Cursor is a position in a tree that can move forward and
+// backward, and be repositioned by key.  A Cursor reads the version
+// of the tree it was created from; later changes to the tree do not
+// affect it.  A Cursor that has moved off either end of the tree is
+// not Valid, and stays that way until First, Last or Seek is called.
+type Cursor[K any, D any] struct {
+	root    *node[K, D]
+	compare func(a, b K) int
+	path    []*node[K, D] // from root to the current node
+}
+
+// Cursor returns a cursor positioned at the smallest key in t,
+// or an invalid cursor if t is empty.
+func (t *Tree[K, D, C]) Cursor() *Cursor[K, D] {
+	c := &Cursor[K, D]{root: t.root, compare: t.cmp()}
+	if t.root != nil {
+		c.path = make([]*node[K, D], 0, t.root.height())
+	}
+	c.First()
+	return c
+}
+
+// Valid returns true iff c is positioned at a key.
+func (c *Cursor[K, D]) Valid() bool {
+	return len(c.path) != 0
+}
+
+// Key returns the key at c, or the zero key if c is not valid.
+func (c *Cursor[K, D]) Key() K {
+	if !c.Valid() {
+		return zero[K]()
+	}
+	return c.path[len(c.path)-1].key
+}
+
+// Value returns the data at c, or the zero data if c is not valid.
+func (c *Cursor[K, D]) Value() D {
+	if !c.Valid() {
+		return zero[D]()
+	}
+	return c.path[len(c.path)-1].data
+}
+
+// First moves c to the smallest key, and returns c.Valid().
+func (c *Cursor[K, D]) First() bool {
+	c.path = c.path[:0]
+	c.leftmost(c.root)
+	return c.Valid()
+}
+
+// Last moves c to the largest key, and returns c.Valid().
+func (c *Cursor[K, D]) Last() bool {
+	c.path = c.path[:0]
+	c.rightmost(c.root)
+	return c.Valid()
+}
+
+// Seek moves c to the smallest key greater than or equal to x,
+// and returns c.Valid().
+func (c *Cursor[K, D]) Seek(x K) bool {
+	c.path = c.path[:0]
+	found := 0 // length of path ending at the best candidate so far
+	for t := c.root; t != nil; {
+		c.path = append(c.path, t)
+		cmp := c.compare(x, t.key)
+		if cmp < 0 {
+			found = len(c.path)
+			t = t.left
+		} else if cmp > 0 {
+			t = t.right
+		} else {
+			found = len(c.path)
+			break
+		}
+	}
+	c.path = c.path[:found]
+	return c.Valid()
+}
+
+// Next moves c to the next larger key, and returns c.Valid().
+func (c *Cursor[K, D]) Next() bool {
+	l := len(c.path)
+	if l == 0 {
+		return false
+	}
+	if x := c.path[l-1]; x.right != nil {
+		c.leftmost(x.right)
+		return true
+	}
+	// Climb until arriving from a left child.
+	for l--; l > 0 && c.path[l-1].right == c.path[l]; l-- {
+	}
+	c.path = c.path[:l]
+	return c.Valid()
+}
+
+// Prev moves c to the next smaller key, and returns c.Valid().
+func (c *Cursor[K, D]) Prev() bool {
+	l := len(c.path)
+	if l == 0 {
+		return false
+	}
+	if x := c.path[l-1]; x.left != nil {
+		c.rightmost(x.left)
+		return true
+	}
+	// Climb until arriving from a right child.
+	for l--; l > 0 && c.path[l-1].left == c.path[l]; l-- {
+	}
+	c.path = c.path[:l]
+	return c.Valid()
+}
+
+// Clone returns a copy of c that moves independently of it.
+// It costs O(log n).
+func (c *Cursor[K, D]) Clone() *Cursor[K, D] {
+	d := *c
+	d.path = append(make([]*node[K, D], 0, cap(c.path)), c.path...)
+	return &d
+}
+
+func (c *Cursor[K, D]) leftmost(t *node[K, D]) {
+	for ; t != nil; t = t.left {
+		c.path = append(c.path, t)
+	}
+}
+
+func (c *Cursor[K, D]) rightmost(t *node[K, D]) {
+	for ; t != nil; t = t.right {
+		c.path = append(c.path, t)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCursor(t *testing.T) {
+	tr := makeTree(0, 100, 3) // 0, 3, ..., 99
+	want := slices.Collect(tr.Keys())
+
+	var got []Int
+	for c := tr.Cursor(); c.Valid(); c.Next() {
+		got = append(got, c.Key())
+		if c.Value() != 10*int(c.Key()) {
+			t.Errorf("Value at %d = %d", c.Key(), c.Value())
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("forward = %v, want %v", got, want)
+	}
+	got = got[:0]
+	c := tr.Cursor()
+	for ok := c.Last(); ok; ok = c.Prev() {
+		got = append(got, c.Key())
+	}
+	slices.Reverse(want)
+	if !slices.Equal(got, want) {
+		t.Errorf("backward = %v, want %v", got, want)
+	}
+
+	for x := Int(-2); x <= 101; x++ {
+		ok := c.Seek(x)
+		k, _, wantOk := tr.LubEqOk(x)
+		if ok != wantOk || ok && c.Key() != k {
+			t.Errorf("Seek(%d) = %d, %v, want %d, %v", x, c.Key(), ok, k, wantOk)
+		}
+		if ok && c.Prev() {
+			if k, _, _ := tr.GlbOk(x); c.Key() != k {
+				t.Errorf("Seek(%d).Prev() = %d, want %d", x, c.Key(), k)
+			}
+		}
+	}
+
+	c.Seek(50)
+	d := c.Clone()
+	d.Next()
+	if c.Key() != 51 || d.Key() != 54 {
+		t.Errorf("after Clone and Next, c at %d and d at %d", c.Key(), d.Key())
+	}
+	tr.Delete(54) // cursors see the version they were made from
+	if !d.Next() || d.Key() != 57 || !d.Prev() || d.Key() != 54 {
+		t.Errorf("d moved to %d", d.Key())
+	}
+
+	if e := (&T[Int, int]{}).Cursor(); e.Valid() || e.Next() || e.Last() || e.Seek(0) {
+		t.Errorf("cursor on empty tree is valid")
+	}
+	c.Last()
+	if c.Next() || c.Valid() || c.Prev() {
+		t.Errorf("cursor past the end is valid")
+	}
+}
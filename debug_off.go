@@ -0,0 +1,11 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build !avldebug
+
+package iter_test
+
+// debugValidate makes every mutating operation validate its result;
+// build with -tags avldebug to enable it.
+const debugValidate = false
@@ -0,0 +1,157 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import "iter"
+
+// A ChangeKind says how an entry differs between two trees.
+type ChangeKind int8
+
+const (
+	Added   ChangeKind = iota // the key is only in the newer tree
+	Removed                   // the key is only in the older tree
+	Changed                   // the key is in both, with different data
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Changed:
+		return "Changed"
+	}
+	return "ChangeKind(?)"
+}
+
+// A Change is one difference between an older and a newer tree.
+// Old is zero for an Added key, and New is zero for a Removed one.
+type Change[K any, D any] struct {
+	Kind     ChangeKind
+	Key      K
+	Old, New D
+}
+
+// Diff returns an iterator over the changes that turn t into u,
+// ordered by key.  Subtrees that t and u share are skipped without
+// being examined, so for two versions of one tree the cost is
+// proportional to the number of changes between them (times log n),
+// not to their size.
+func Diff[K any, D comparable, C Comparer[K]](t, u *Tree[K, D, C]) iter.Seq[Change[K, D]] {
+	return DiffFunc(t, u, func(x, y D) bool { return x == y })
+}
+
+// DiffFunc is like Diff, but compares data with eq.
+func DiffFunc[K any, D any, C Comparer[K]](t, u *Tree[K, D, C], eq func(x, y D) bool) iter.Seq[Change[K, D]] {
+	return func(yield func(Change[K, D]) bool) {
+		diff(t.root, u.root, t.cmp(), eq, yield)
+	}
+}
+
+// A diffItem is a pending part of an in-order traversal: either a
+// whole subtree, or just the node itself, its left subtree done.
+type diffItem[K any, D any] struct {
+	n     *node[K, D]
+	whole bool
+}
+
+// diffStack is one side of a diff, a stack of pending diffItems whose
+// top holds the smallest keys.
+type diffStack[K any, D any] []diffItem[K, D]
+
+func (s *diffStack[K, D]) top() diffItem[K, D] {
+	return (*s)[len(*s)-1]
+}
+
+func (s *diffStack[K, D]) pop() {
+	*s = (*s)[:len(*s)-1]
+}
+
+// push adds n, as a whole subtree, if it is not nil.
+func (s *diffStack[K, D]) push(n *node[K, D]) {
+	if n != nil {
+		*s = append(*s, diffItem[K, D]{n, true})
+	}
+}
+
+// expand replaces the whole subtree on top of s by its parts.
+func (s *diffStack[K, D]) expand() {
+	n := s.top().n
+	s.pop()
+	s.push(n.right)
+	*s = append(*s, diffItem[K, D]{n, false})
+	s.push(n.left)
+}
+
+// drain yields a change of kind k for every key remaining in s.
+func (s *diffStack[K, D]) drain(k ChangeKind, yield func(Change[K, D]) bool) bool {
+	for len(*s) > 0 {
+		if s.top().whole {
+			s.expand()
+			continue
+		}
+		n := s.top().n
+		s.pop()
+		c := Change[K, D]{Kind: k, Key: n.key}
+		if k == Removed {
+			c.Old = n.data
+		} else {
+			c.New = n.data
+		}
+		if !yield(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// diff yields the changes from t to u, returning false if yield does.
+// Both trees are traversed in order, each as a stack of pending items.
+// When both tops are whole subtrees, the taller is expanded first, so
+// that a subtree shared by t and u reaches the top of both stacks at
+// once and can be skipped.
+func diff[K any, D any](t, u *node[K, D], compare func(a, b K) int, eq func(x, y D) bool, yield func(Change[K, D]) bool) bool {
+	if t == u {
+		return true
+	}
+	capacity := 2*max(t.height(), u.height()) + 1
+	ts, us := make(diffStack[K, D], 0, capacity), make(diffStack[K, D], 0, capacity)
+	ts.push(t)
+	us.push(u)
+	for len(ts) > 0 && len(us) > 0 {
+		a, b := ts.top(), us.top()
+		switch {
+		case a.whole && b.whole && a.n == b.n:
+			ts.pop()
+			us.pop()
+		case a.whole && (!b.whole || a.n.height() >= b.n.height()):
+			ts.expand()
+		case b.whole:
+			us.expand()
+		default:
+			switch c := compare(a.n.key, b.n.key); {
+			case c < 0:
+				ts.pop()
+				if !yield(Change[K, D]{Kind: Removed, Key: a.n.key, Old: a.n.data}) {
+					return false
+				}
+			case c > 0:
+				us.pop()
+				if !yield(Change[K, D]{Kind: Added, Key: b.n.key, New: b.n.data}) {
+					return false
+				}
+			default:
+				ts.pop()
+				us.pop()
+				if a.n != b.n && !eq(a.n.data, b.n.data) &&
+					!yield(Change[K, D]{Kind: Changed, Key: a.n.key, Old: a.n.data, New: b.n.data}) {
+					return false
+				}
+			}
+		}
+	}
+	return ts.drain(Removed, yield) && us.drain(Added, yield)
+}
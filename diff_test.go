@@ -0,0 +1,94 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	a := makeTree(0, 500, 1)
+	for trial := 0; trial < 50; trial++ {
+		b := a.Copy()
+		want := map[Int]Change[Int, int]{}
+		for range r.IntN(20) {
+			k := Int(r.IntN(600))
+			old, had := a.Get(k)
+			switch r.IntN(3) {
+			case 0:
+				b.Delete(k)
+				if had {
+					want[k] = Change[Int, int]{Kind: Removed, Key: k, Old: old}
+				} else {
+					delete(want, k)
+				}
+			case 1:
+				b.Insert(k, -int(k))
+				if had && old == -int(k) {
+					delete(want, k)
+				} else if had {
+					want[k] = Change[Int, int]{Kind: Changed, Key: k, Old: old, New: -int(k)}
+				} else {
+					want[k] = Change[Int, int]{Kind: Added, Key: k, New: -int(k)}
+				}
+			case 2:
+				// Reinserting the same data is not a change.
+				if had {
+					b.Insert(k, old)
+					delete(want, k)
+				}
+			}
+		}
+		got := slices.Collect(Diff(a, b))
+		if !slices.IsSortedFunc(got, func(x, y Change[Int, int]) int { return x.Key.Compare(y.Key) }) {
+			t.Errorf("changes are not in key order: %v", got)
+		}
+		if len(got) != len(want) {
+			t.Errorf("trial %d: %d changes, want %d", trial, len(got), len(want))
+		}
+		for _, c := range got {
+			if want[c.Key] != c {
+				t.Errorf("trial %d: change %+v, want %+v", trial, c, want[c.Key])
+			}
+		}
+		if Equals(a, b) != (len(want) == 0) {
+			t.Errorf("trial %d: Equals = %v with %d changes", trial, Equals(a, b), len(want))
+		}
+		a = b
+	}
+
+	// Diff against unrelated trees with the same contents finds nothing.
+	c := Collect(a.All())
+	if n := len(slices.Collect(Diff(a, c))); n != 0 || !Equals(a, c) || !a.Equiv(c, intEq) {
+		t.Errorf("Diff of equal, unshared trees found %d changes", n)
+	}
+	for range Diff(a, &T[Int, int]{}) {
+		break // stopping early is allowed
+	}
+}
+
+func TestDiffSkipsSharedSubtrees(t *testing.T) {
+	const n = 1 << 12
+	a := makeTree(0, n, 1)
+	b := a.Copy()
+	b.Insert(n/3, 0)
+	b.Delete(2 * n / 3)
+	compared := 0
+	count := func(x, y int) bool {
+		compared++
+		return x == y
+	}
+	got := slices.Collect(DiffFunc(a, b, count))
+	if len(got) != 2 || got[0].Kind != Changed || got[1].Kind != Removed {
+		t.Errorf("Diff = %v", got)
+	}
+	// Only nodes on the two copied paths should be compared.
+	if compared > 4*int(a.root.height()) {
+		t.Errorf("Diff compared %d entries of a tree of height %d", compared, a.root.height())
+	}
+}
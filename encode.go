@@ -0,0 +1,226 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Trees are encoded as their size followed by their keys and data in
+// increasing key order, so that decoding builds a balanced tree in
+// O(n) time without comparing keys more than once each.  The binary
+// and gob encodings are a gob stream of the size and then each key
+// and datum; encoding of keys and data is left to gob, which uses
+// their GobEncode or MarshalBinary methods if they have them.  The
+// JSON encoding is an array of [key, data] pairs, whose elements are
+// encoded by encoding/json.
+//
+// Decoding into a Func tree requires that its comparison function
+// already be set, as by NewFunc; the other trees are ready as zero values.
+
+// An Encoder writes trees to an output stream, one element at a time,
+// so that a tree need not be buffered in memory to be written.
+type Encoder struct {
+	enc *gob.Encoder
+}
+
+// NewEncoder returns an Encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{enc: gob.NewEncoder(w)}
+}
+
+// Encode writes t, which is a *Tree (or *T, *Ordered or *Func), to e.
+func (e *Encoder) Encode(t treeEncoder) error {
+	return t.encode(e.enc)
+}
+
+// A Decoder reads trees from an input stream written by an Encoder.
+type Decoder struct {
+	dec *gob.Decoder
+}
+
+// NewDecoder returns a Decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{dec: gob.NewDecoder(r)}
+}
+
+// Decode reads the next tree from d into t, which is a *Tree
+// (or *T, *Ordered or *Func), replacing its contents.
+func (d *Decoder) Decode(t treeDecoder) error {
+	return t.decode(d.dec)
+}
+
+type treeEncoder interface {
+	encode(enc *gob.Encoder) error
+}
+
+type treeDecoder interface {
+	decode(dec *gob.Decoder) error
+}
+
+func (t *Tree[K, D, C]) encode(enc *gob.Encoder) error {
+	if err := enc.Encode(t.Size()); err != nil {
+		return err
+	}
+	var err error
+	t.root.doAll2Flat(func(k K, d D) bool {
+		if err = enc.Encode(k); err == nil {
+			err = enc.Encode(d)
+		}
+		return err == nil
+	})
+	return err
+}
+
+func (t *Tree[K, D, C]) decode(dec *gob.Decoder) error {
+	var n int
+	if err := dec.Decode(&n); err != nil {
+		return err
+	}
+	if n < 0 {
+		return fmt.Errorf("decoding tree: negative size %d", n)
+	}
+	b := streamBuilder[K, D]{dec: dec, compare: t.cmp()}
+	root, err := b.build(n)
+	if err != nil {
+		return err
+	}
+	t.root, t.size = root, n
+	t.check()
+	return nil
+}
+
+// A streamBuilder builds a balanced tree from the keys and data
+// in a gob stream, checking that the keys are strictly increasing.
+type streamBuilder[K any, D any] struct {
+	dec     *gob.Decoder
+	compare func(a, b K) int
+	prev    *K
+	i       int
+}
+
+// build returns a balanced tree of the next n elements of b's stream.
+// It is buildBalanced, reading the elements in order as it goes.
+func (b *streamBuilder[K, D]) build(n int) (*node[K, D], error) {
+	if n == 0 {
+		return nil, nil
+	}
+	mid := n / 2
+	left, err := b.build(mid)
+	if err != nil {
+		return nil, err
+	}
+	m := &node[K, D]{}
+	if err := b.dec.Decode(&m.key); err != nil {
+		return nil, fmt.Errorf("decoding key %d: %w", b.i, noEOF(err))
+	}
+	if err := b.dec.Decode(&m.data); err != nil {
+		return nil, fmt.Errorf("decoding data %d: %w", b.i, noEOF(err))
+	}
+	if b.prev != nil && b.compare(*b.prev, m.key) >= 0 {
+		return nil, fmt.Errorf("decoding tree: key %v at position %d does not follow %v", m.key, b.i, *b.prev)
+	}
+	b.prev = &m.key
+	b.i++
+	right, err := b.build(n - mid - 1)
+	if err != nil {
+		return nil, err
+	}
+	m.left, m.right = left, right
+	m.fix()
+	return m, nil
+}
+
+// noEOF reports a premature end of input as such.
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+func (t *Tree[K, D, C]) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(t); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+func (t *Tree[K, D, C]) UnmarshalBinary(data []byte) error {
+	return NewDecoder(bytes.NewReader(data)).Decode(t)
+}
+
+// GobEncode implements gob.GobEncoder, using the binary encoding.
+func (t *Tree[K, D, C]) GobEncode() ([]byte, error) {
+	return t.MarshalBinary()
+}
+
+// GobDecode implements gob.GobDecoder, using the binary encoding.
+func (t *Tree[K, D, C]) GobDecode(data []byte) error {
+	return t.UnmarshalBinary(data)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (t *Tree[K, D, C]) MarshalJSON() ([]byte, error) {
+	b := []byte{'['}
+	var err error
+	t.root.doAll2Flat(func(k K, d D) bool {
+		var kd []byte
+		if kd, err = json.Marshal([2]any{k, d}); err != nil {
+			return false
+		}
+		if len(b) > 1 {
+			b = append(b, ',')
+		}
+		b = append(b, kd...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(b, ']'), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *Tree[K, D, C]) UnmarshalJSON(data []byte) error {
+	var raw [][2]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	var err error
+	seq := func(yield func(K, D) bool) {
+		for i, kd := range raw {
+			var k K
+			var d D
+			if err = json.Unmarshal(kd[0], &k); err != nil {
+				err = fmt.Errorf("decoding key %d: %w", i, err)
+				return
+			}
+			if err = json.Unmarshal(kd[1], &d); err != nil {
+				err = fmt.Errorf("decoding data %d: %w", i, err)
+				return
+			}
+			if !yield(k, d) {
+				return
+			}
+		}
+	}
+	u := t.empty()
+	if _, ferr := fromSorted(u, seq); ferr != nil {
+		return ferr
+	}
+	if err != nil {
+		return err
+	}
+	*t = *u
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestEncoding(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 7, 1000} {
+		tr := makeTree(0, n, 1)
+
+		b, err := tr.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var u T[Int, int]
+		if err := u.UnmarshalBinary(b); err != nil {
+			t.Fatalf("UnmarshalBinary: %v", err)
+		}
+		checkDecoded(t, "binary", tr, &u)
+
+		j, err := json.Marshal(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var v T[Int, int]
+		if err := json.Unmarshal(j, &v); err != nil {
+			t.Fatalf("UnmarshalJSON: %v", err)
+		}
+		checkDecoded(t, "JSON", tr, &v)
+
+		// A tree inside a gob-encoded struct.
+		type doc struct {
+			Name string
+			Tree *T[Int, int]
+		}
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(doc{"x", tr}); err != nil {
+			t.Fatal(err)
+		}
+		var w doc
+		if err := gob.NewDecoder(&buf).Decode(&w); err != nil {
+			t.Fatalf("gob: %v", err)
+		}
+		checkDecoded(t, "gob", tr, w.Tree)
+	}
+
+	if j, _ := json.Marshal(makeTree(1, 3, 1)); string(j) != "[[1,10],[2,20]]" {
+		t.Errorf("JSON = %s", j)
+	}
+	f := NewFunc[string, int](func(a, b string) int { return strings.Compare(b, a) })
+	if err := json.Unmarshal([]byte(`[["b",1],["a",2]]`), f); err != nil || f.String() != "b:1; a:2" {
+		t.Errorf("JSON into Func = %v, %v", f, err)
+	}
+	var o Ordered[string, int]
+	if err := json.Unmarshal([]byte(`[["b",1],["a",2]]`), &o); err == nil {
+		t.Errorf("JSON with keys out of order decoded as %v", &o)
+	}
+}
+
+func checkDecoded(t *testing.T, how string, want, got *T[Int, int]) {
+	t.Helper()
+	if !Equals(want, got) {
+		t.Errorf("%s: decoded %v, want %v", how, got, want)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("%s: %v", how, err)
+	}
+}
+
+func TestEncoderStream(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	trees := []*T[Int, int]{makeTree(0, 100, 1), {}, makeTree(50, 60, 3)}
+	for _, tr := range trees {
+		if err := enc.Encode(tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	whole := buf.Bytes()
+	dec := NewDecoder(bytes.NewReader(whole))
+	for i, want := range trees {
+		got := makeTree(0, 5, 1) // decoding replaces the contents
+		if err := dec.Decode(got); err != nil {
+			t.Fatalf("tree %d: %v", i, err)
+		}
+		checkDecoded(t, "stream", want, got)
+	}
+	if err := dec.Decode(&T[Int, int]{}); err != io.EOF {
+		t.Errorf("Decode at end of stream = %v", err)
+	}
+
+	// A truncated stream is an error, and leaves the tree unchanged.
+	tr := makeTree(0, 5, 1)
+	err := NewDecoder(bytes.NewReader(whole[:len(whole)/3])).Decode(tr)
+	if !errors.Is(err, io.ErrUnexpectedEOF) || tr.Size() != 5 {
+		t.Errorf("truncated Decode = %v, leaving %v", err, tr)
+	}
+}
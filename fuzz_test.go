@@ -0,0 +1,207 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Fuzz operations, each encoded as an opcode byte followed, for all
+// but fuzzDeleteMin, fuzzDeleteMax and fuzzCopy, by an argument byte.
+const (
+	fuzzInsert = iota
+	fuzzDelete
+	fuzzDeleteMin
+	fuzzDeleteMax
+	fuzzCopy
+	fuzzUnion
+	fuzzIntersection
+	fuzzDifference
+	fuzzOps
+)
+
+const fuzzKeys = 64
+
+// model is the reference implementation that the fuzzer checks T against.
+type model map[Int]int
+
+func (m model) String() string {
+	var b []string
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		b = append(b, fmt.Sprintf("%v:%v", k, m[k]))
+	}
+	return strings.Join(b, "; ")
+}
+
+type version struct {
+	tree   *T[Int, int]
+	model  model
+	string string
+}
+
+// keepFirst makes Union and Intersection choose data predictably.
+func keepFirst(x, y int) (int, bool) { return x, true }
+
+func FuzzTree(f *testing.F) {
+	seed := func(ops ...byte) { f.Add(ops) }
+	ins := func(keys ...byte) []byte {
+		var b []byte
+		for _, k := range keys {
+			b = append(b, fuzzInsert, k)
+		}
+		return b
+	}
+	del := func(k byte) []byte { return []byte{fuzzDelete, k} }
+
+	// Insertions needing each rotation.
+	seed(ins(1, 2, 3)...)
+	seed(ins(3, 2, 1)...)
+	seed(ins(1, 3, 2)...)
+	seed(ins(3, 1, 2)...)
+	// Deletions from the left leaving the right subtree balanced,
+	// right-heavy and left-heavy, and their mirror images.
+	seed(append(ins(2, 1, 4, 3, 5), del(1)...)...)
+	seed(append(ins(2, 1, 3, 4), del(1)...)...)
+	seed(append(ins(2, 1, 4, 3), del(1)...)...)
+	seed(append(ins(4, 5, 2, 3, 1), del(5)...)...)
+	seed(append(ins(3, 4, 2, 1), del(4)...)...)
+	seed(append(ins(3, 4, 1, 2), del(4)...)...)
+	// The same, deleting an interior node and the extremes.
+	seed(append(ins(5, 3, 8, 2, 4, 7, 10, 1, 6, 9, 11, 12), del(5)...)...)
+	seed(append(ins(2, 1, 4, 3, 5), fuzzDeleteMin)...)
+	seed(append(ins(4, 5, 2, 3, 1), fuzzDeleteMax)...)
+	seed(append(ins(3, 4, 1, 2), fuzzDeleteMax, fuzzDeleteMax, fuzzDeleteMin)...)
+	// Set operations between versions.
+	seed(append(append(ins(1, 5, 9, 13, 17, 21), fuzzCopy), append(ins(3, 5, 7, 21, 40), fuzzUnion, 0, fuzzCopy, fuzzIntersection, 0, fuzzDifference, 1)...)...)
+
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		tr, m := &T[Int, int]{}, model{}
+		var versions []version
+		for i := 0; i < len(ops); i++ {
+			op := ops[i] % fuzzOps
+			var arg byte
+			if op != fuzzDeleteMin && op != fuzzDeleteMax && op != fuzzCopy {
+				if i++; i == len(ops) {
+					break
+				}
+				arg = ops[i]
+			}
+			k := Int(arg % fuzzKeys)
+			var desc string
+			switch op {
+			case fuzzInsert:
+				desc = fmt.Sprintf("Insert(%d)", k)
+				tr.Insert(k, i)
+				m[k] = i
+			case fuzzDelete:
+				desc = fmt.Sprintf("Delete(%d)", k)
+				d, ok := tr.DeleteOk(k)
+				if md, mok := m[k]; d != md || ok != mok {
+					t.Fatalf("%s = %d, %v, want %d, %v", desc, d, ok, md, mok)
+				}
+				delete(m, k)
+			case fuzzDeleteMin, fuzzDeleteMax:
+				keys := slices.Sorted(maps.Keys(m))
+				var mk Int
+				var k Int
+				var ok bool
+				if op == fuzzDeleteMin {
+					desc = "DeleteMin"
+					k, _, ok = tr.DeleteMinOk()
+					if len(keys) > 0 {
+						mk = keys[0]
+					}
+				} else {
+					desc = "DeleteMax"
+					k, _, ok = tr.DeleteMaxOk()
+					if len(keys) > 0 {
+						mk = keys[len(keys)-1]
+					}
+				}
+				if ok != (len(keys) > 0) || k != mk {
+					t.Fatalf("%s = %d, %v, want %d", desc, k, ok, mk)
+				}
+				delete(m, k)
+			case fuzzCopy:
+				desc = "Copy"
+				versions = append(versions, version{tr.Copy(), maps.Clone(m), m.String()})
+			default:
+				if len(versions) == 0 {
+					continue
+				}
+				v := versions[int(arg)%len(versions)]
+				switch op {
+				case fuzzUnion:
+					desc = "Union"
+					tr = Union(tr, v.tree, keepFirst)
+					for vk, vd := range v.model {
+						if _, ok := m[vk]; !ok {
+							m[vk] = vd
+						}
+					}
+				case fuzzIntersection:
+					desc = "Intersection"
+					tr = Intersection(tr, v.tree, keepFirst)
+					maps.DeleteFunc(m, func(k Int, _ int) bool { _, ok := v.model[k]; return !ok })
+				case fuzzDifference:
+					desc = "Difference"
+					tr = Difference(tr, v.tree, nil)
+					maps.DeleteFunc(m, func(k Int, _ int) bool { _, ok := v.model[k]; return ok })
+				}
+				// Results may share nodes with their inputs; keep
+				// editing a private copy of the tree header.
+				tr = tr.Copy()
+			}
+			checkModel(t, fmt.Sprintf("after op %d, %s", i, desc), tr, m, k)
+		}
+		for j, v := range versions {
+			if got := v.tree.String(); got != v.string {
+				t.Errorf("version %d changed from %s to %s", j, v.string, got)
+			}
+			checkModel(t, fmt.Sprintf("version %d", j), v.tree, v.model, 0)
+		}
+	})
+}
+
+// checkModel compares tr with m, probing lookups around k.
+func checkModel(t *testing.T, when string, tr *T[Int, int], m model, k Int) {
+	t.Helper()
+	if err := tr.Validate(); err != nil {
+		t.Fatalf("%s: %v", when, err)
+	}
+	if tr.Size() != len(m) {
+		t.Fatalf("%s: Size() = %d, want %d", when, tr.Size(), len(m))
+	}
+	keys := slices.Sorted(maps.Keys(m))
+	if got := slices.Collect(tr.Keys()); !slices.Equal(got, keys) {
+		t.Fatalf("%s: keys = %v, want %v", when, got, keys)
+	}
+	if got, want := tr.String(), m.String(); got != want {
+		t.Fatalf("%s: String() = %s, want %s", when, got, want)
+	}
+	for x := k - 1; x <= k+1; x++ {
+		if got, want := tr.Find(x), m[x]; got != want {
+			t.Fatalf("%s: Find(%d) = %d, want %d", when, x, got, want)
+		}
+		i, found := slices.BinarySearch(keys, x)
+		glb, lub := i-1, i
+		if found {
+			lub++
+		}
+		check := func(name string, gotK Int, gotD int, gotOk bool, j int) {
+			if ok := j >= 0 && j < len(keys); gotOk != ok || ok && (gotK != keys[j] || gotD != m[keys[j]]) {
+				t.Fatalf("%s: %s(%d) = %d, %d, %v", when, name, x, gotK, gotD, gotOk)
+			}
+		}
+		gk, gd, gok := tr.GlbOk(x)
+		check("Glb", gk, gd, gok, glb)
+		lk, ld, lok := tr.LubOk(x)
+		check("Lub", lk, ld, lok, lub)
+	}
+}
@@ -1,5 +1,5 @@
 module github.com/dr2chase/iter_test
 
-go 1.23
+go 1.24
 
 require github.com/dr2chase/xiter v1.23.0
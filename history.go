@@ -0,0 +1,229 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"iter"
+	"slices"
+)
+
+// A History records numbered versions of a tree, supporting undo and
+// redo and queries of earlier versions.  Because trees are persistent,
+// each version shares all its unchanged nodes with its neighbors.
+//
+// Versions are numbered from zero in the order they are committed,
+// and numbers are never reused.  Committing after an Undo discards the
+// versions that could have been redone, except that tagged ones are
+// detached: they remain available to At, Since and Tagged, but Undo
+// and Redo no longer reach them.  Versions older than the most
+// recent n (see KeepLast) are dropped unless they are tagged or current.
+type History[K any, D any, C Comparer[K]] struct {
+	log      []revision[K, D, C] // retained versions, in increasing order
+	head     int                 // index in log of the current version
+	next     int                 // number of the next version committed
+	keepLast int                 // if > 0, the number of versions retained
+}
+
+type revision[K any, D any, C Comparer[K]] struct {
+	number   int
+	tree     Tree[K, D, C]
+	tags     []string
+	detached bool // abandoned by a Commit after an Undo, but tagged
+}
+
+// NewHistory returns a history whose current version, number 0, is t.
+func NewHistory[K any, D any, C Comparer[K]](t *Tree[K, D, C]) *History[K, D, C] {
+	return &History[K, D, C]{log: []revision[K, D, C]{{tree: *t}}, next: 1}
+}
+
+// Version returns the number of the current version.
+func (h *History[K, D, C]) Version() int {
+	return h.log[h.head].number
+}
+
+// Current returns a copy of the current version; editing the copy
+// does not change h.
+func (h *History[K, D, C]) Current() *Tree[K, D, C] {
+	return h.log[h.head].tree.Copy()
+}
+
+// Commit records t as a new version, makes it current and returns
+// its number.
+func (h *History[K, D, C]) Commit(t *Tree[K, D, C]) int {
+	for i := h.head + 1; i < len(h.log); i++ {
+		h.log[i].detached = true
+	}
+	h.log = append(h.log, revision[K, D, C]{number: h.next, tree: *t})
+	h.head = len(h.log) - 1
+	h.next++
+	h.prune()
+	return h.Version()
+}
+
+// Undo makes the retained version before the current one current,
+// and returns false if there is none.
+func (h *History[K, D, C]) Undo() bool {
+	for i := h.head - 1; i >= 0; i-- {
+		if !h.log[i].detached {
+			h.head = i
+			return true
+		}
+	}
+	return false
+}
+
+// Redo reverses an Undo, and returns false if there is nothing to redo.
+func (h *History[K, D, C]) Redo() bool {
+	for i := h.head + 1; i < len(h.log); i++ {
+		if !h.log[i].detached {
+			h.head = i
+			return true
+		}
+	}
+	return false
+}
+
+// At returns a read-only snapshot of version v and true,
+// or false if v was never committed or is no longer retained.
+func (h *History[K, D, C]) At(v int) (*Snapshot[K, D, C], bool) {
+	i, ok := h.find(v)
+	if !ok {
+		return nil, false
+	}
+	return &Snapshot[K, D, C]{t: h.log[i].tree, version: v}, true
+}
+
+// Since returns the changes that turn version v into the current
+// version, ordered by key, and true, or false if v is not retained.
+// Data are compared with eq to find changed entries.
+func (h *History[K, D, C]) Since(v int, eq func(x, y D) bool) (iter.Seq[Change[K, D]], bool) {
+	i, ok := h.find(v)
+	if !ok {
+		return nil, false
+	}
+	// Capture the versions now; later changes to h may move them in h.log.
+	from, to, compare := h.log[i].tree.root, h.log[h.head].tree.root, h.log[i].tree.cmp()
+	return func(yield func(Change[K, D]) bool) {
+		diff(from, to, compare, eq, yield)
+	}, true
+}
+
+// Tag attaches tag to version v, which will then be retained
+// regardless of KeepLast, and returns false if v is not retained.
+func (h *History[K, D, C]) Tag(v int, tag string) bool {
+	i, ok := h.find(v)
+	if ok && !slices.Contains(h.log[i].tags, tag) {
+		h.log[i].tags = append(h.log[i].tags, tag)
+	}
+	return ok
+}
+
+// Untag removes tag from every version, allowing them to be dropped.
+func (h *History[K, D, C]) Untag(tag string) {
+	for i := range h.log {
+		h.log[i].tags = slices.DeleteFunc(h.log[i].tags, func(s string) bool { return s == tag })
+	}
+	h.prune()
+}
+
+// Tagged returns the number of the latest version tagged with tag and true,
+// or false if there is none.
+func (h *History[K, D, C]) Tagged(tag string) (int, bool) {
+	for i := len(h.log) - 1; i >= 0; i-- {
+		if slices.Contains(h.log[i].tags, tag) {
+			return h.log[i].number, true
+		}
+	}
+	return 0, false
+}
+
+// KeepLast sets the retention policy of h to keep the last n versions
+// committed, in addition to tagged versions and the current one,
+// and drops any others now.  If n <= 0, every version is kept.
+func (h *History[K, D, C]) KeepLast(n int) {
+	h.keepLast = n
+	h.prune()
+}
+
+// Versions returns the numbers of the retained versions, in order.
+func (h *History[K, D, C]) Versions() []int {
+	vs := make([]int, len(h.log))
+	for i := range h.log {
+		vs[i] = h.log[i].number
+	}
+	return vs
+}
+
+// find returns the index in h.log of version v.
+func (h *History[K, D, C]) find(v int) (int, bool) {
+	return slices.BinarySearchFunc(h.log, v, func(x revision[K, D, C], v int) int {
+		return x.number - v
+	})
+}
+
+// prune drops the versions that the retention policy does not keep,
+// and detached versions no longer tagged, so that their nodes can be
+// garbage collected.
+func (h *History[K, D, C]) prune() {
+	cut := len(h.log) - h.keepLast
+	if h.keepLast <= 0 {
+		cut = 0
+	}
+	j := 0
+	for i := range h.log {
+		r := &h.log[i]
+		if len(r.tags) == 0 && (r.detached || i < cut && i != h.head) {
+			continue
+		}
+		if i == h.head {
+			h.head = j
+		}
+		h.log[j] = h.log[i]
+		j++
+	}
+	clear(h.log[j:])
+	h.log = h.log[:j]
+}
+
+// A Snapshot is a read-only view of one version in a History.
+type Snapshot[K any, D any, C Comparer[K]] struct {
+	t       Tree[K, D, C]
+	version int
+}
+
+// Version returns the number of the version s shows.
+func (s *Snapshot[K, D, C]) Version() int {
+	return s.version
+}
+
+func (s *Snapshot[K, D, C]) Get(x K) (D, bool) {
+	return s.t.Get(x)
+}
+
+func (s *Snapshot[K, D, C]) Contains(x K) bool {
+	return s.t.Contains(x)
+}
+
+func (s *Snapshot[K, D, C]) Size() int {
+	return s.t.Size()
+}
+
+func (s *Snapshot[K, D, C]) All() iter.Seq2[K, D] {
+	return s.t.All()
+}
+
+func (s *Snapshot[K, D, C]) Range(lo, hi Bound[K]) iter.Seq2[K, D] {
+	return s.t.Range(lo, hi)
+}
+
+func (s *Snapshot[K, D, C]) String() string {
+	return s.t.String()
+}
+
+// Tree returns a copy of the version s shows, which may be edited
+// (and perhaps committed) without changing the history.
+func (s *Snapshot[K, D, C]) Tree() *Tree[K, D, C] {
+	return s.t.Copy()
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func intEq(x, y int) bool { return x == y }
+
+func TestHistory(t *testing.T) {
+	h := NewHistory(makeTree(0, 10, 1))
+	for i := 1; i <= 5; i++ {
+		tr := h.Current()
+		tr.Insert(Int(10+i), i)
+		if v := h.Commit(tr); v != i {
+			t.Fatalf("Commit returned version %d, want %d", v, i)
+		}
+	}
+	s3, ok := h.At(3)
+	if !ok || s3.Size() != 13 || s3.Contains(14) || !s3.Contains(13) {
+		t.Fatalf("At(3) = %v, %v", s3, ok)
+	}
+
+	if !h.Undo() || !h.Undo() || h.Version() != 3 || h.Current().Size() != 13 {
+		t.Errorf("after two Undos, version %d has %d keys", h.Version(), h.Current().Size())
+	}
+	if !h.Redo() || h.Version() != 4 {
+		t.Errorf("after Redo, version = %d", h.Version())
+	}
+
+	// Committing after an Undo discards version 5.
+	tr := h.Current()
+	tr.Delete(0)
+	tr.Insert(1, -1)
+	if v := h.Commit(tr); v != 6 || h.Redo() {
+		t.Errorf("Commit after Undo gave version %d", v)
+	}
+	if _, ok := h.At(5); ok {
+		t.Errorf("version 5 survived a commit after undo")
+	}
+
+	changes, ok := h.Since(2, intEq)
+	if !ok {
+		t.Fatalf("Since(2) failed")
+	}
+	var got []string
+	for c := range changes {
+		got = append(got, fmt.Sprintf("%v %d %d->%d", c.Kind, c.Key, c.Old, c.New))
+	}
+	want := []string{"Removed 0 0->0", "Changed 1 10->-1", "Added 13 0->3", "Added 14 0->4"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Since(2) = %v, want %v", got, want)
+	}
+	if s3.Size() != 13 || s3.Tree().Size() != 13 {
+		t.Errorf("snapshot changed")
+	}
+}
+
+func TestHistoryRetention(t *testing.T) {
+	h := NewHistory(&Ordered[string, int]{})
+	for i := 1; i <= 10; i++ {
+		tr := h.Current()
+		tr.Insert(fmt.Sprint(i), i)
+		h.Commit(tr)
+		if i == 2 {
+			h.Tag(i, "release")
+		}
+	}
+	h.KeepLast(3)
+	if got := h.Versions(); !slices.Equal(got, []int{2, 8, 9, 10}) {
+		t.Errorf("after KeepLast(3), versions = %v", got)
+	}
+	if v, ok := h.Tagged("release"); !ok || v != 2 {
+		t.Errorf("Tagged(release) = %d, %v", v, ok)
+	}
+
+	// Undo skips versions that were dropped.
+	h.Undo()
+	h.Undo()
+	if !h.Undo() || h.Version() != 2 || h.Undo() {
+		t.Errorf("Undo reached version %d", h.Version())
+	}
+	h.Untag("release")
+	if got := h.Versions(); !slices.Equal(got, []int{2, 8, 9, 10}) {
+		t.Errorf("current version was dropped; versions = %v", got)
+	}
+	h.Redo()
+	h.KeepLast(1)
+	if got := h.Versions(); !slices.Equal(got, []int{8, 10}) {
+		t.Errorf("after KeepLast(1) at version 8, versions = %v", got)
+	}
+}
+
+// TestHistoryCommitAfterUndo checks that committing after an Undo
+// keeps the tagged versions that could have been redone, detached
+// from undo and redo, and drops the rest.
+func TestHistoryCommitAfterUndo(t *testing.T) {
+	h := NewHistory(&Ordered[string, int]{})
+	for i := 1; i <= 4; i++ {
+		tr := h.Current()
+		tr.Insert(fmt.Sprint(i), i)
+		h.Commit(tr)
+	}
+	h.Tag(3, "experiment")
+	h.Undo()
+	h.Undo()
+	h.Undo()
+	tr := h.Current()
+	tr.Insert("x", 0)
+	if v := h.Commit(tr); v != 5 {
+		t.Errorf("Commit after Undo = version %d, want 5", v)
+	}
+	if got := h.Versions(); !slices.Equal(got, []int{0, 1, 3, 5}) {
+		t.Errorf("versions = %v, want [0 1 3 5]", got)
+	}
+	if v, ok := h.Tagged("experiment"); !ok || v != 3 {
+		t.Errorf("Tagged(experiment) = %d, %v", v, ok)
+	}
+	if s, ok := h.At(3); !ok || s.String() != "1:1; 2:2; 3:3" {
+		t.Errorf("At(3) = %v, %v", s, ok)
+	}
+	if h.Redo() {
+		t.Errorf("Redo after Commit reached version %d", h.Version())
+	}
+	if !h.Undo() || h.Version() != 1 {
+		t.Errorf("Undo after Commit reached version %d, want 1", h.Version())
+	}
+	if !h.Redo() || h.Version() != 5 {
+		t.Errorf("Redo reached version %d, want 5", h.Version())
+	}
+	h.Untag("experiment")
+	if got := h.Versions(); !slices.Equal(got, []int{0, 1, 5}) {
+		t.Errorf("after Untag, versions = %v, want [0 1 5]", got)
+	}
+}
+
+// TestHistorySinceThenPrune checks that the iterator returned by Since
+// reports the versions asked for, even if they are dropped meanwhile.
+func TestHistorySinceThenPrune(t *testing.T) {
+	h := NewHistory(&Ordered[string, int]{})
+	for i := 1; i <= 3; i++ {
+		tr := h.Current()
+		tr.Insert(fmt.Sprint(i), i)
+		h.Commit(tr)
+	}
+	seq, ok := h.Since(1, func(x, y int) bool { return x == y })
+	if !ok {
+		t.Fatalf("Since(1) failed")
+	}
+	h.KeepLast(1)
+	if got := h.Versions(); !slices.Equal(got, []int{3}) {
+		t.Errorf("after KeepLast(1), versions = %v", got)
+	}
+	var keys []string
+	for c := range seq {
+		keys = append(keys, c.Key)
+	}
+	if !slices.Equal(keys, []string{"2", "3"}) {
+		t.Errorf("Since(1) after KeepLast yielded %v, want [2 3]", keys)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+)
+
+// An Interval is the half-open interval [Start, End).
+type Interval[P cmp.Ordered] struct {
+	Start, End P
+}
+
+// Compare orders intervals by Start, then by End.
+func (i Interval[P]) Compare(j Interval[P]) int {
+	if c := cmp.Compare(i.Start, j.Start); c != 0 {
+		return c
+	}
+	return cmp.Compare(i.End, j.End)
+}
+
+// Overlaps returns true iff i and j have a point in common;
+// an empty interval overlaps nothing.
+func (i Interval[P]) Overlaps(j Interval[P]) bool {
+	return i.Start < i.End && j.Start < j.End && i.Start < j.End && j.Start < i.End
+}
+
+// Contains returns true iff p is in i.
+func (i Interval[P]) Contains(p P) bool {
+	return i.Start <= p && p < i.End
+}
+
+func (i Interval[P]) String() string {
+	return fmt.Sprintf("[%v, %v)", i.Start, i.End)
+}
+
+// An IntervalTree is a persistent map from intervals to data D,
+// supporting queries for the intervals that overlap an interval or
+// contain a point.  It is an Augmented tree keyed by interval, in
+// which every subtree's summary is the greatest End within it, so a
+// query visits only the subtrees that can hold a match, and takes
+// O(min(n, m log n)) time to find m intervals.  Any number of intervals
+// may share a Start, but each interval appears at most once;
+// inserting it again replaces its data.  The zero IntervalTree is
+// empty and ready to use.
+type IntervalTree[P cmp.Ordered, D any] struct {
+	a Augmented[Interval[P], D, maxEnd[P], MethodCompare[Interval[P]]]
+}
+
+// maxEnd is the summary of an IntervalTree's subtree, the greatest End
+// of the intervals in it, if there are any.
+type maxEnd[P cmp.Ordered] struct {
+	end P
+	ok  bool
+}
+
+// NewIntervalTree returns an empty IntervalTree.
+func NewIntervalTree[P cmp.Ordered, D any]() *IntervalTree[P, D] {
+	t := &IntervalTree[P, D]{}
+	t.init()
+	return t
+}
+
+// init supplies t's monoid, if it is the zero IntervalTree.
+func (t *IntervalTree[P, D]) init() {
+	if t.a.mon.Combine != nil {
+		return
+	}
+	t.a.mon = Monoid[Interval[P], D, maxEnd[P]]{
+		Measure: func(i Interval[P], _ D) maxEnd[P] { return maxEnd[P]{i.End, true} },
+		Combine: func(a, b maxEnd[P]) maxEnd[P] {
+			if !a.ok || b.ok && b.end > a.end {
+				return b
+			}
+			return a
+		},
+	}
+}
+
+// Insert adds i with data d to t, and returns the data i had before,
+// or zero.  It panics if i is empty, that is, if i.End <= i.Start.
+func (t *IntervalTree[P, D]) Insert(i Interval[P], d D) D {
+	if i.End <= i.Start {
+		panic(fmt.Sprintf("IntervalTree.Insert of empty interval %v", i))
+	}
+	t.init()
+	return t.a.Insert(i, d)
+}
+
+// Delete removes i from t, returning its data and true,
+// or the zero value and false if i was not in t.
+func (t *IntervalTree[P, D]) Delete(i Interval[P]) (D, bool) {
+	t.init()
+	return t.a.DeleteOk(i)
+}
+
+// Get returns the data for i and true, or false if i is not in t.
+func (t *IntervalTree[P, D]) Get(i Interval[P]) (D, bool) {
+	return t.a.Get(i)
+}
+
+func (t *IntervalTree[P, D]) Size() int {
+	return t.a.Size()
+}
+
+func (t *IntervalTree[P, D]) Copy() *IntervalTree[P, D] {
+	return &IntervalTree[P, D]{a: *t.a.Copy()}
+}
+
+// All returns an iterator over the intervals in t and their data,
+// ordered by Start, then End.
+func (t *IntervalTree[P, D]) All() iter.Seq2[Interval[P], D] {
+	return t.a.All()
+}
+
+// Overlapping returns an iterator over the intervals in t that
+// overlap q, and their data, ordered by Start, then End.
+func (t *IntervalTree[P, D]) Overlapping(q Interval[P]) iter.Seq2[Interval[P], D] {
+	return func(yield func(Interval[P], D) bool) {
+		if q.Start < q.End {
+			// Those with Start < q.End and End > q.Start.
+			overlapping(t.a.t.root, q.Start, func(s P) bool { return s < q.End }, yield)
+		}
+	}
+}
+
+// Stabbing returns an iterator over the intervals in t that
+// contain p, and their data, ordered by Start, then End.
+func (t *IntervalTree[P, D]) Stabbing(p P) iter.Seq2[Interval[P], D] {
+	return func(yield func(Interval[P], D) bool) {
+		// Those with Start <= p and End > p.
+		overlapping(t.a.t.root, p, func(s P) bool { return s <= p }, yield)
+	}
+}
+
+// overlapping yields, in order, the intervals in n that end after lo
+// and whose starts satisfy startOk, which holds for a prefix of the
+// keys.  It returns false if yield does.
+func overlapping[P cmp.Ordered, D any](n *node[Interval[P], augItem[D, maxEnd[P]]], lo P,
+	startOk func(P) bool, yield func(Interval[P], D) bool) bool {
+	for n != nil && n.data.sum.end > lo {
+		if !overlapping(n.left, lo, startOk, yield) {
+			return false
+		}
+		if !startOk(n.key.Start) {
+			return true // nor will any to its right
+		}
+		if n.key.End > lo && !yield(n.key, n.data.data) {
+			return false
+		}
+		n = n.right
+	}
+	return true
+}
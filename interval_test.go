@@ -0,0 +1,111 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestIntervalTree(t *testing.T) {
+	r := rand.New(rand.NewPCG(5, 6))
+	tr := NewIntervalTree[int, int]()
+	model := map[Interval[int]]int{}
+	var old []*IntervalTree[int, int]
+	var oldModels []map[Interval[int]]int
+	for i := 0; i < 1500; i++ {
+		s := r.IntN(200)
+		iv := Interval[int]{s, s + 1 + r.IntN(30)}
+		if r.IntN(4) == 0 && len(model) > 0 {
+			// Delete a specific interval, often one sharing a start with others.
+			for k := range model {
+				iv = k
+				break
+			}
+			if d, ok := tr.Delete(iv); !ok || d != model[iv] {
+				t.Fatalf("Delete(%v) = %d, %v", iv, d, ok)
+			}
+			delete(model, iv)
+		} else {
+			tr.Insert(iv, i)
+			model[iv] = i
+		}
+		if i%150 == 0 {
+			old = append(old, tr.Copy())
+			c := map[Interval[int]]int{}
+			for k, v := range model {
+				c[k] = v
+			}
+			oldModels = append(oldModels, c)
+		}
+		q := r.IntN(240)
+		checkIntervals(t, tr, model, Interval[int]{q, q + 1 + r.IntN(20)}, q)
+	}
+	for i, o := range old {
+		for q := 0; q < 240; q += 7 {
+			checkIntervals(t, o, oldModels[i], Interval[int]{q, q + 3}, q)
+		}
+	}
+	// Empty queries overlap nothing, whether by Overlaps or the tree.
+	for q := 0; q < 240; q += 13 {
+		checkIntervals(t, tr, model, Interval[int]{q, q}, q)
+		checkIntervals(t, tr, model, Interval[int]{q + 5, q}, q)
+	}
+	if (Interval[int]{92, 92}).Overlaps(Interval[int]{85, 100}) {
+		t.Errorf("an empty interval overlaps a nonempty one")
+	}
+	if _, ok := tr.Delete(Interval[int]{-5, -1}); ok {
+		t.Errorf("Delete of an absent interval succeeded")
+	}
+}
+
+func checkIntervals(t *testing.T, tr *IntervalTree[int, int], model map[Interval[int]]int, q Interval[int], p int) {
+	t.Helper()
+	var overlap, stab []Interval[int]
+	for iv := range model {
+		if iv.Overlaps(q) {
+			overlap = append(overlap, iv)
+		}
+		if iv.Contains(p) {
+			stab = append(stab, iv)
+		}
+	}
+	slices.SortFunc(overlap, Interval[int].Compare)
+	slices.SortFunc(stab, Interval[int].Compare)
+	var got []Interval[int]
+	for iv, d := range tr.Overlapping(q) {
+		if d != model[iv] {
+			t.Fatalf("Overlapping(%v) gave %v with data %d, want %d", q, iv, d, model[iv])
+		}
+		got = append(got, iv)
+	}
+	if !slices.Equal(got, overlap) {
+		t.Fatalf("Overlapping(%v) = %v, want %v", q, got, overlap)
+	}
+	got = got[:0]
+	for iv := range tr.Stabbing(p) {
+		got = append(got, iv)
+	}
+	if !slices.Equal(got, stab) {
+		t.Fatalf("Stabbing(%d) = %v, want %v", p, got, stab)
+	}
+}
+
+func TestIntervalTreeZero(t *testing.T) {
+	var tr IntervalTree[int, string]
+	if _, ok := tr.Delete(Interval[int]{1, 2}); ok || tr.Size() != 0 {
+		t.Errorf("Delete from the zero IntervalTree succeeded")
+	}
+	tr.Insert(Interval[int]{1, 5}, "a")
+	tr.Insert(Interval[int]{3, 8}, "b")
+	var got []string
+	for _, d := range tr.Stabbing(4) {
+		got = append(got, d)
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("Stabbing(4) = %v, want [a b]", got)
+	}
+}
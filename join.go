@@ -0,0 +1,298 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"fmt"
+)
+
+// Split returns the elements of t with keys less than x, the data
+// for x, and the elements with keys greater than x.  found reports
+// whether x was present in t.  Split takes O(log n) time and t is
+// unchanged; the results share most of their nodes with t.
+func (t *Tree[K, D, C]) Split(x K) (less *Tree[K, D, C], d D, greater *Tree[K, D, C], found bool) {
+	l, m, r := t.root.split(x, t.cmp())
+	less, greater = t.withRoot(l), t.withRoot(r)
+	if m == nil {
+		return less, zero[D](), greater, false
+	}
+	return less, m.data, greater, true
+}
+
+// SplitAt returns the first i elements of t in key order, and the rest.
+// SplitAt takes O(log n) time and t is unchanged.
+func (t *Tree[K, D, C]) SplitAt(i int) (before, after *Tree[K, D, C]) {
+	l, r := t.root.splitAt(max(i, 0))
+	return t.withRoot(l), t.withRoot(r)
+}
+
+// Partition returns the elements of t for which pred is true, and
+// those for which it is false.  Subtrees that lie entirely on one
+// side are shared with t.
+func (t *Tree[K, D, C]) Partition(pred func(k K, d D) bool) (yes, no *Tree[K, D, C]) {
+	y, n := t.root.partition(pred)
+	return t.withRoot(y), t.withRoot(n)
+}
+
+// Join returns a tree containing the elements of lo and hi, which must not
+// overlap; every key in lo must be less than every key in hi, and otherwise
+// Join panics.  Join takes O(log n) time and lo and hi are unchanged.
+func Join[K any, D any, C Comparer[K]](lo, hi *Tree[K, D, C]) *Tree[K, D, C] {
+	if lo.Size() == 0 {
+		return hi
+	}
+	if hi.Size() == 0 {
+		return lo
+	}
+	if l, h := lo.root.maximum().key, hi.root.minimum().key; lo.cmp()(l, h) >= 0 {
+		panic(fmt.Sprintf("Join of overlapping trees, %v >= %v", l, h))
+	}
+	r := join2(lo.root, hi.root)
+	return lo.withRoot(r)
+}
+
+// The split and join operations here follow Blelloch, Ferizovic and Sun,
+// "Just Join for Parallel Ordered Sets".  Like aInsert and aDelete, they
+// never modify a node reachable from their inputs; new nodes are copies.
+
+// fix recomputes the height and size of t from its children.
+func (t *node[K, D]) fix() {
+	t.height_ = 1 + max(t.left.height(), t.right.height())
+	t.size_ = 1 + int32(t.left.size()+t.right.size())
+}
+
+// with returns a copy of t with children l and r.
+func (t *node[K, D]) with(l, r *node[K, D]) *node[K, D] {
+	t = t.copy()
+	t.left, t.right = l, r
+	t.fix()
+	return t
+}
+
+// join returns a balanced tree containing l, m's key and data, and r,
+// where all keys in l are less than m.key and all keys in r are greater.
+// m itself is not modified or used in the result.
+func join[K any, D any](l, m, r *node[K, D]) *node[K, D] {
+	if l.height() > r.height()+1 {
+		return joinRight(l, m, r)
+	}
+	if r.height() > l.height()+1 {
+		return joinLeft(l, m, r)
+	}
+	return m.with(l, r)
+}
+
+// joinRight is join for the case that l is the higher tree;
+// m and r are attached somewhere along l's right spine.
+func joinRight[K any, D any](l, m, r *node[K, D]) *node[K, D] {
+	var t *node[K, D]
+	if c := l.right; c.height() <= r.height()+1 {
+		t = m.with(c, r)
+	} else {
+		t = joinRight(c, m, r)
+	}
+	l = l.with(l.left, t)
+	if t.height() <= l.left.height()+1 {
+		return l
+	}
+	// l and t are fresh, as aRightIsHigh requires.
+	return l.aRightIsHigh(nil, nil)
+}
+
+// joinLeft is the mirror image of joinRight.
+func joinLeft[K any, D any](l, m, r *node[K, D]) *node[K, D] {
+	var t *node[K, D]
+	if c := r.left; c.height() <= l.height()+1 {
+		t = m.with(l, c)
+	} else {
+		t = joinLeft(l, m, c)
+	}
+	r = r.with(t, r.right)
+	if t.height() <= r.right.height()+1 {
+		return r
+	}
+	return r.aLeftIsHigh(nil, nil)
+}
+
+// join2 returns a balanced tree containing l and r,
+// where all keys in l are less than all keys in r.
+func join2[K any, D any](l, r *node[K, D]) *node[K, D] {
+	if l == nil {
+		return r
+	}
+	if r == nil {
+		return l
+	}
+	m, l := l.aDeleteMax(nil)
+	return join(l, m, r)
+}
+
+// split returns the subtrees of t holding keys less than and greater
+// than key, and the node for key itself, if it is present in t.
+func (t *node[K, D]) split(key K, compare func(a, b K) int) (less, found, greater *node[K, D]) {
+	if t == nil {
+		return nil, nil, nil
+	}
+	cmp := compare(key, t.key)
+	if cmp < 0 {
+		less, found, greater = t.left.split(key, compare)
+		return less, found, join(greater, t, t.right)
+	}
+	if cmp > 0 {
+		less, found, greater = t.right.split(key, compare)
+		return join(t.left, t, less), found, greater
+	}
+	return t.left, t, t.right
+}
+
+// splitAt returns the subtrees of t holding its first i nodes and the rest.
+func (t *node[K, D]) splitAt(i int) (before, after *node[K, D]) {
+	if t == nil {
+		return nil, nil
+	}
+	if i == 0 {
+		return nil, t
+	}
+	if i >= t.size() {
+		return t, nil
+	}
+	if ls := t.left.size(); i <= ls {
+		before, after = t.left.splitAt(i)
+		return before, join(after, t, t.right)
+	}
+	before, after = t.right.splitAt(i - t.left.size() - 1)
+	return join(t.left, t, before), after
+}
+
+func (t *node[K, D]) partition(pred func(k K, d D) bool) (yes, no *node[K, D]) {
+	if t == nil {
+		return nil, nil
+	}
+	ly, ln := t.left.partition(pred)
+	ry, rn := t.right.partition(pred)
+	if pred(t.key, t.data) {
+		return joinOrRebuild(t, ly, t, ry), join2(ln, rn)
+	}
+	return join2(ly, ry), joinOrRebuild(t, ln, t, rn)
+}
+
+// combine returns the node to place between l and r for a key whose
+// data in the two inputs is t.data and u.data, or nil if f drops it.
+// A nil f keeps t's data.
+func combine[K any, D any](t, u *node[K, D], f func(x, y D) (D, bool)) *node[K, D] {
+	if f == nil {
+		return t
+	}
+	c, ok := f(t.data, u.data)
+	if !ok {
+		return nil
+	}
+	m := *t
+	m.data = c
+	return &m
+}
+
+// joinOrRebuild returns t if its children are unchanged, else the join
+// of l, m and r, or of l and r if m is nil.
+func joinOrRebuild[K any, D any](t, l, m, r *node[K, D]) *node[K, D] {
+	if m == t && l == t.left && r == t.right {
+		return t
+	}
+	if m == nil {
+		return join2(l, r)
+	}
+	return join(l, m, r)
+}
+
+// union, intersection and difference make their two recursive calls
+// in parallel if p is not nil and allows it; see ParallelUnion.
+
+func union[K any, D any](t, u *node[K, D], f func(x, y D) (D, bool), compare func(a, b K) int, p *forker) *node[K, D] {
+	if t == nil {
+		return u
+	}
+	if u == nil || t == u && f == nil {
+		return t
+	}
+	ul, m, ur := u.split(t.key, compare)
+	var l, r *node[K, D]
+	if p.forks(t.size() + u.size()) {
+		l, r = fork(p,
+			func() *node[K, D] { return union(t.left, ul, f, compare, p) },
+			func() *node[K, D] { return union(t.right, ur, f, compare, p) })
+	} else {
+		l, r = union(t.left, ul, f, compare, p), union(t.right, ur, f, compare, p)
+	}
+	if m == nil {
+		return joinOrRebuild(t, l, t, r)
+	}
+	return joinOrRebuild(t, l, combine(t, m, f), r)
+}
+
+func intersection[K any, D any](t, u *node[K, D], f func(x, y D) (D, bool), compare func(a, b K) int, p *forker) *node[K, D] {
+	if t == nil || u == nil {
+		return nil
+	}
+	if t == u && f == nil {
+		return t
+	}
+	ul, m, ur := u.split(t.key, compare)
+	var l, r *node[K, D]
+	if p.forks(t.size() + u.size()) {
+		l, r = fork(p,
+			func() *node[K, D] { return intersection(t.left, ul, f, compare, p) },
+			func() *node[K, D] { return intersection(t.right, ur, f, compare, p) })
+	} else {
+		l, r = intersection(t.left, ul, f, compare, p), intersection(t.right, ur, f, compare, p)
+	}
+	if m == nil {
+		return join2(l, r)
+	}
+	return joinOrRebuild(t, l, combine(t, m, f), r)
+}
+
+func difference[K any, D any](t, u *node[K, D], f func(x, y D) (D, bool), compare func(a, b K) int, p *forker) *node[K, D] {
+	if t == nil || t == u && f == nil {
+		return nil
+	}
+	if u == nil {
+		return t
+	}
+	ul, m, ur := u.split(t.key, compare)
+	var l, r *node[K, D]
+	if p.forks(t.size() + u.size()) {
+		l, r = fork(p,
+			func() *node[K, D] { return difference(t.left, ul, f, compare, p) },
+			func() *node[K, D] { return difference(t.right, ur, f, compare, p) })
+	} else {
+		l, r = difference(t.left, ul, f, compare, p), difference(t.right, ur, f, compare, p)
+	}
+	if m == nil {
+		return joinOrRebuild(t, l, t, r)
+	}
+	if f == nil {
+		return join2(l, r)
+	}
+	return joinOrRebuild(t, l, combine(t, m, f), r)
+}
+
+func symmetricDifference[K any, D any](t, u *node[K, D], compare func(a, b K) int) *node[K, D] {
+	if t == u {
+		return nil
+	}
+	if t == nil {
+		return u
+	}
+	if u == nil {
+		return t
+	}
+	l, m, r := u.split(t.key, compare)
+	l = symmetricDifference(t.left, l, compare)
+	r = symmetricDifference(t.right, r, compare)
+	if m == nil {
+		return joinOrRebuild(t, l, t, r)
+	}
+	return join2(l, r)
+}
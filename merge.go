@@ -0,0 +1,84 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import "slices"
+
+// A Conflict is a key that ours and theirs both changed, differently,
+// from base.  Present says which of base, ours and theirs (in that
+// order) contain the key; the data of an absent version is zero.
+type Conflict[K any, D any] struct {
+	Key                K
+	Base, Ours, Theirs D
+	Present            [3]bool
+}
+
+// Merge3 merges the changes that ours and theirs each made to base.
+// A key changed by only one side, or changed identically by both,
+// takes that change.  A key that both changed differently is a
+// conflict; resolve is called with its data in the three versions and
+// returns the merged data and true, or false to leave the key out of
+// the result.  If resolve is nil, conflicts take ours.  Every conflict
+// is also returned, in key order, however it was resolved.
+//
+// The changes are found with Diff, so subtrees that a side shares
+// with base are skipped, and the result shares nodes with ours.
+func Merge3[K any, D comparable, C Comparer[K]](base, ours, theirs *Tree[K, D, C],
+	resolve func(k K, base, ours, theirs D, present [3]bool) (D, bool)) (*Tree[K, D, C], []Conflict[K, D]) {
+	return Merge3Func(base, ours, theirs, resolve, func(x, y D) bool { return x == y })
+}
+
+// Merge3Func is like Merge3, but compares data with eq.
+func Merge3Func[K any, D any, C Comparer[K]](base, ours, theirs *Tree[K, D, C],
+	resolve func(k K, base, ours, theirs D, present [3]bool) (D, bool),
+	eq func(x, y D) bool) (*Tree[K, D, C], []Conflict[K, D]) {
+	compare := base.cmp()
+	o := slices.Collect(DiffFunc(base, ours, eq))
+	t := slices.Collect(DiffFunc(base, theirs, eq))
+	if len(t) == 0 {
+		return ours, nil
+	}
+	b := ours.Transient()
+	apply := func(c Change[K, D]) {
+		if c.Kind == Removed {
+			b.Delete(c.Key)
+		} else {
+			b.Insert(c.Key, c.New)
+		}
+	}
+	var conflicts []Conflict[K, D]
+	for len(t) > 0 {
+		c := 1
+		if len(o) > 0 {
+			c = compare(o[0].Key, t[0].Key)
+		}
+		if c < 0 {
+			o = o[1:] // changed only in ours, so already in b
+			continue
+		}
+		if c > 0 {
+			apply(t[0]) // changed only in theirs
+			t = t[1:]
+			continue
+		}
+		oc, tc := o[0], t[0]
+		o, t = o[1:], t[1:]
+		if oc.Kind == tc.Kind && (oc.Kind == Removed || eq(oc.New, tc.New)) {
+			continue // the same change on both sides
+		}
+		conflict := Conflict[K, D]{Key: oc.Key, Base: oc.Old, Ours: oc.New, Theirs: tc.New,
+			Present: [3]bool{oc.Kind != Added, oc.Kind != Removed, tc.Kind != Removed}}
+		conflicts = append(conflicts, conflict)
+		if resolve == nil {
+			continue
+		}
+		if d, ok := resolve(conflict.Key, conflict.Base, conflict.Ours, conflict.Theirs, conflict.Present); ok {
+			b.Insert(conflict.Key, d)
+		} else {
+			b.Delete(conflict.Key)
+		}
+	}
+	return b.Persistent(), conflicts
+}
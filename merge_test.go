@@ -0,0 +1,83 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := makeTree(0, 100, 1)
+	ours, theirs := base.Copy(), base.Copy()
+
+	// Changes made by one side only.
+	ours.Insert(5, 1)
+	theirs.Insert(6, 2)
+	ours.Delete(7)
+	theirs.Delete(8)
+	// The same changes made by both sides.
+	ours.Insert(200, 3)
+	theirs.Insert(200, 3)
+	ours.Delete(9)
+	theirs.Delete(9)
+	// Conflicting changes: changed differently, removed and changed,
+	// and added differently.
+	ours.Insert(10, 4)
+	theirs.Insert(10, 5)
+	ours.Delete(11)
+	theirs.Insert(11, 6)
+	ours.Insert(300, 7)
+	theirs.Insert(300, 8)
+
+	var calls []Int
+	sum := func(k Int, b, o, th int, present [3]bool) (int, bool) {
+		calls = append(calls, k)
+		if !present[1] || !present[2] {
+			return 0, false // deletion wins
+		}
+		return o + th, true
+	}
+	m, conflicts := Merge3(base, ours, theirs, sum)
+	if !slices.Equal(calls, []Int{10, 11, 300}) {
+		t.Errorf("resolve called for %v", calls)
+	}
+	want := []Conflict[Int, int]{
+		{Key: 10, Base: 100, Ours: 4, Theirs: 5, Present: [3]bool{true, true, true}},
+		{Key: 11, Base: 110, Theirs: 6, Present: [3]bool{true, false, true}},
+		{Key: 300, Ours: 7, Theirs: 8, Present: [3]bool{false, true, true}},
+	}
+	if !slices.Equal(conflicts, want) {
+		t.Errorf("conflicts = %v, want %v", conflicts, want)
+	}
+
+	expect := base.Copy()
+	expect.Insert(5, 1)
+	expect.Insert(6, 2)
+	expect.Delete(7)
+	expect.Delete(8)
+	expect.Delete(9)
+	expect.Insert(10, 9)
+	expect.Delete(11)
+	expect.Insert(200, 3)
+	expect.Insert(300, 15)
+	if !Equals(m, expect) {
+		t.Errorf("Merge3 = %v\nwant %v", m, expect)
+	}
+	if err := m.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	// Without a resolver, conflicts take ours.
+	m, conflicts = Merge3(base, ours, theirs, nil)
+	if len(conflicts) != 3 || m.Find(10) != 4 || m.Contains(11) || m.Find(300) != 7 || m.Contains(8) {
+		t.Errorf("Merge3 with nil resolve = %v", m)
+	}
+
+	// Merging an unchanged side returns the other.
+	if m, c := Merge3(base, ours, base, sum); m != ours || c != nil {
+		t.Errorf("Merge3 with unchanged theirs did not return ours")
+	}
+}
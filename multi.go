@@ -0,0 +1,247 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"math"
+)
+
+// A TreeMulti is a persistent multimap from keys K to data D, with
+// keys ordered by the comparer C.  A key may appear any number of
+// times; its values are kept in the order they were added.  Most
+// code will use one of its aliases, Multi, OrderedMulti or FuncMulti.
+//
+// Each value is stored in its own node, keyed by the pair of its key
+// and a sequence number, so counting and removing all the values of a
+// key takes O(log n) time.
+type TreeMulti[K any, D any, C Comparer[K]] struct {
+	t   Tree[mkey[K], D, multiCompare[K, C]]
+	seq uint64 // sequence number of the next value added
+}
+
+// Multi is a TreeMulti whose keys are ordered by their Compare method.
+// The zero Multi is empty and ready to use.
+type Multi[K Comparable[K], D any] = TreeMulti[K, D, MethodCompare[K]]
+
+// OrderedMulti is a TreeMulti whose keys are ordered by cmp.Compare.
+// The zero OrderedMulti is empty and ready to use.
+type OrderedMulti[K cmp.Ordered, D any] = TreeMulti[K, D, OrderedCompare[K]]
+
+// FuncMulti is a TreeMulti whose keys are ordered by a comparison function.
+// Use NewFuncMulti to create one.
+type FuncMulti[K any, D any] = TreeMulti[K, D, FuncCompare[K]]
+
+// NewFuncMulti returns an empty FuncMulti ordered by cmp.
+func NewFuncMulti[K any, D any](cmp func(a, b K) int) *FuncMulti[K, D] {
+	m := &FuncMulti[K, D]{}
+	m.t.order.order = FuncCompare[K]{cmp}
+	return m
+}
+
+// mkey is the key of a multimap node; seq orders the values of a key.
+type mkey[K any] struct {
+	key K
+	seq uint64
+}
+
+// multiCompare orders mkeys by key using C, then by sequence number.
+type multiCompare[K any, C Comparer[K]] struct {
+	order C
+}
+
+func (m multiCompare[K, C]) Compare(a, b mkey[K]) int {
+	if c := m.order.Compare(a.key, b.key); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.seq, b.seq)
+}
+
+// bounds returns the inclusive bounds enclosing every value of x.
+func (m *TreeMulti[K, D, C]) bounds(x K) (lo, hi Bound[mkey[K]]) {
+	return Inclusive(mkey[K]{x, 0}), Inclusive(mkey[K]{x, math.MaxUint64})
+}
+
+// empty returns an empty multimap with the same ordering as m.
+func (m *TreeMulti[K, D, C]) empty() *TreeMulti[K, D, C] {
+	return &TreeMulti[K, D, C]{t: Tree[mkey[K], D, multiCompare[K, C]]{order: m.t.order}}
+}
+
+// Add adds the value d for key x, after any values x already has.
+func (m *TreeMulti[K, D, C]) Add(x K, d D) {
+	m.t.Insert(mkey[K]{x, m.seq}, d)
+	m.seq++
+}
+
+// RemoveOne removes the first (oldest) value for x and returns it
+// and true, or returns the zero value and false if x is not in m.
+func (m *TreeMulti[K, D, C]) RemoveOne(x K) (D, bool) {
+	k, _, ok := m.t.LubEqOk(mkey[K]{x, 0})
+	if !ok || m.t.order.order.Compare(k.key, x) != 0 {
+		return zero[D](), false
+	}
+	return m.t.DeleteOk(k)
+}
+
+// RemoveAll removes every value for x, and returns how many there were.
+func (m *TreeMulti[K, D, C]) RemoveAll(x K) int {
+	lo, hi := m.bounds(x)
+	compare := m.t.cmp()
+	less, _, rest := m.t.root.split(lo.key, compare)
+	_, _, greater := rest.split(hi.key, compare)
+	n := m.t.size
+	m.t.root = join2(less, greater)
+	m.t.size = m.t.root.size()
+	m.t.check()
+	return n - m.t.size
+}
+
+// Count returns the number of values for x, in O(log n) time.
+func (m *TreeMulti[K, D, C]) Count(x K) int {
+	return m.t.CountRange(m.bounds(x))
+}
+
+// Has returns true iff x has at least one value in m.
+func (m *TreeMulti[K, D, C]) Has(x K) bool {
+	return m.Count(x) > 0
+}
+
+// Values returns an iterator over the values for x, in the order
+// they were added.
+func (m *TreeMulti[K, D, C]) Values(x K) iter.Seq[D] {
+	return func(yield func(d D) bool) {
+		for _, d := range m.t.Range(m.bounds(x)) {
+			if !yield(d) {
+				return
+			}
+		}
+	}
+}
+
+// All returns an iterator over the key and data pairs in m, ordered
+// by key, and for equal keys in the order they were added.
+func (m *TreeMulti[K, D, C]) All() iter.Seq2[K, D] {
+	return func(yield func(k K, d D) bool) {
+		m.t.root.doAll2Flat(func(k mkey[K], d D) bool {
+			return yield(k.key, d)
+		})
+	}
+}
+
+// Size returns the number of values in m, counting every key as
+// many times as it appears.
+func (m *TreeMulti[K, D, C]) Size() int {
+	return m.t.Size()
+}
+
+func (m *TreeMulti[K, D, C]) IsEmpty() bool {
+	return m.t.IsEmpty()
+}
+
+func (m *TreeMulti[K, D, C]) Copy() *TreeMulti[K, D, C] {
+	c := *m
+	return &c
+}
+
+func (m *TreeMulti[K, D, C]) String() string {
+	var b string
+	for k, d := range m.All() {
+		if b != "" {
+			b += "; "
+		}
+		b += fmt.Sprintf("%v:%v", k, d)
+	}
+	return b
+}
+
+// The multiset operations treat m and u as bags of keys, pairing the
+// i'th value of a key in m with the i'th value of that key in u.
+// They run in O(n+m) time and build a new, perfectly balanced tree.
+
+// Union returns a multimap in which each key appears as many times as
+// it does in whichever of m and u has more of it.  Values come from m,
+// then from the surplus values of u.
+func (m *TreeMulti[K, D, C]) Union(u *TreeMulti[K, D, C]) *TreeMulti[K, D, C] {
+	return m.merge(u, func(out []D, t, u []D) []D {
+		if len(u) > len(t) {
+			return append(append(out, t...), u[len(t):]...)
+		}
+		return append(out, t...)
+	})
+}
+
+// Intersect returns a multimap in which each key appears as many times
+// as it does in whichever of m and u has less of it, with values from m.
+func (m *TreeMulti[K, D, C]) Intersect(u *TreeMulti[K, D, C]) *TreeMulti[K, D, C] {
+	return m.merge(u, func(out []D, t, u []D) []D {
+		return append(out, t[:min(len(t), len(u))]...)
+	})
+}
+
+// Minus returns a multimap in which each key of m appears as many fewer
+// times as it appears in u, dropping m's oldest values first.
+func (m *TreeMulti[K, D, C]) Minus(u *TreeMulti[K, D, C]) *TreeMulti[K, D, C] {
+	return m.merge(u, func(out []D, t, u []D) []D {
+		return append(out, t[min(len(t), len(u)):]...)
+	})
+}
+
+// Sum returns a multimap containing every value of m and of u; for
+// each key, m's values come before u's.
+func (m *TreeMulti[K, D, C]) Sum(u *TreeMulti[K, D, C]) *TreeMulti[K, D, C] {
+	return m.merge(u, func(out []D, t, u []D) []D {
+		return append(append(out, t...), u...)
+	})
+}
+
+// merge walks m and u together a key at a time, using f to choose the
+// values for each key from the values of that key in m and in u, and
+// builds the result, renumbering the values as it goes.
+func (m *TreeMulti[K, D, C]) merge(u *TreeMulti[K, D, C], f func(out []D, t, u []D) []D) *TreeMulti[K, D, C] {
+	compare := m.t.order.order.Compare
+	a, b := m.groups(), u.groups()
+	var pairs []pair[mkey[K], D]
+	var vals []D
+	emit := func(k K, t, u []D) {
+		vals = f(vals[:0], t, u)
+		for _, d := range vals {
+			pairs = append(pairs, pair[mkey[K], D]{mkey[K]{k, uint64(len(pairs))}, d})
+		}
+	}
+	for len(a) > 0 || len(b) > 0 {
+		switch {
+		case len(b) == 0 || len(a) > 0 && compare(a[0].key, b[0].key) < 0:
+			emit(a[0].key, a[0].data, nil)
+			a = a[1:]
+		case len(a) == 0 || compare(a[0].key, b[0].key) > 0:
+			emit(b[0].key, nil, b[0].data)
+			b = b[1:]
+		default:
+			emit(a[0].key, a[0].data, b[0].data)
+			a, b = a[1:], b[1:]
+		}
+	}
+	r := m.empty()
+	r.t.root, r.t.size = buildBalanced(pairs), len(pairs)
+	r.t.check()
+	r.seq = uint64(len(pairs))
+	return r
+}
+
+// groups returns the values of m grouped by key, in key order.
+func (m *TreeMulti[K, D, C]) groups() []pair[K, []D] {
+	compare := m.t.order.order.Compare
+	var g []pair[K, []D]
+	for k, d := range m.All() {
+		if l := len(g); l > 0 && compare(g[l-1].key, k) == 0 {
+			g[l-1].data = append(g[l-1].data, d)
+			continue
+		}
+		g = append(g, pair[K, []D]{k, []D{d}})
+	}
+	return g
+}
@@ -0,0 +1,73 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"slices"
+	"testing"
+)
+
+func makeMulti(kvs ...string) *OrderedMulti[byte, string] {
+	m := &OrderedMulti[byte, string]{}
+	for _, kv := range kvs {
+		m.Add(kv[0], kv[1:])
+	}
+	return m
+}
+
+func TestMulti(t *testing.T) {
+	m := makeMulti("b1", "a1", "b2", "c1", "b3", "a2")
+	if got := m.String(); got != "97:1; 97:2; 98:1; 98:2; 98:3; 99:1" {
+		t.Errorf("String = %s", got)
+	}
+	if m.Count('b') != 3 || m.Count('z') != 0 || m.Size() != 6 {
+		t.Errorf("Count(b) = %d, Size = %d", m.Count('b'), m.Size())
+	}
+	if got := slices.Collect(m.Values('b')); !slices.Equal(got, []string{"1", "2", "3"}) {
+		t.Errorf("Values(b) = %v", got)
+	}
+
+	c := m.Copy()
+	if d, ok := m.RemoveOne('b'); !ok || d != "1" {
+		t.Errorf("RemoveOne(b) = %s, %v", d, ok)
+	}
+	if _, ok := m.RemoveOne('z'); ok {
+		t.Errorf("RemoveOne(z) succeeded")
+	}
+	m.Add('b', "4")
+	if got := slices.Collect(m.Values('b')); !slices.Equal(got, []string{"2", "3", "4"}) {
+		t.Errorf("Values(b) after RemoveOne and Add = %v", got)
+	}
+	if n := m.RemoveAll('b'); n != 3 || m.Has('b') || m.Size() != 3 {
+		t.Errorf("RemoveAll(b) = %d, leaving %v", n, m)
+	}
+	if c.Count('b') != 3 {
+		t.Errorf("copy changed: %v", c)
+	}
+}
+
+func TestMultiSetOps(t *testing.T) {
+	m := makeMulti("a1", "a2", "b1", "c1", "c2", "c3")
+	u := makeMulti("a3", "c4", "d1", "a4", "a5")
+	for _, tc := range []struct {
+		name string
+		got  *OrderedMulti[byte, string]
+		want string
+	}{
+		{"Union", m.Union(u), "97:1; 97:2; 97:5; 98:1; 99:1; 99:2; 99:3; 100:1"},
+		{"Intersect", m.Intersect(u), "97:1; 97:2; 99:1"},
+		{"Minus", m.Minus(u), "98:1; 99:2; 99:3"},
+		{"Sum", m.Sum(u), "97:1; 97:2; 97:3; 97:4; 97:5; 98:1; 99:1; 99:2; 99:3; 99:4; 100:1"},
+	} {
+		if got := tc.got.String(); got != tc.want {
+			t.Errorf("%s = %s, want %s", tc.name, got, tc.want)
+		}
+		checkSizes(t, tc.got.t.root)
+		tc.got.Add('a', "x")
+		if vs := slices.Collect(tc.got.Values('a')); len(vs) == 0 || vs[len(vs)-1] != "x" {
+			t.Errorf("%s then Add: values of a = %v", tc.name, vs)
+		}
+	}
+}
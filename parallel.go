@@ -0,0 +1,199 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelCutoff is the combined size of the subtrees below which
+// the parallel operations run sequentially; for smaller subtrees,
+// starting a goroutine costs more than it saves.
+const parallelCutoff = 1 << 11
+
+// A KeyRange is the range of keys between Lo and Hi.
+type KeyRange[K any] struct {
+	Lo, Hi Bound[K]
+}
+
+// SplitPoints returns k ranges of keys, in order, that together cover
+// every key and that each hold about the same number of elements of t,
+// so that work on t can be divided among k goroutines.  If t has fewer
+// than k elements, there is one range for each.  SplitPoints takes
+// O(k log n) time.
+func (t *Tree[K, D, C]) SplitPoints(k int) []KeyRange[K] {
+	n := t.Size()
+	k = min(max(k, 1), n)
+	if k == 0 {
+		return nil
+	}
+	ranges := make([]KeyRange[K], k)
+	for i := 1; i < k; i++ {
+		m := t.root.selectNode(i * n / k)
+		ranges[i-1].Hi = Exclusive(m.key)
+		ranges[i].Lo = Inclusive(m.key)
+	}
+	return ranges
+}
+
+// ParallelDo calls f for every key and data pair in t, using up to
+// workers goroutines, or GOMAXPROCS if workers is not positive.
+// The calls are made concurrently and in no particular order.
+func (t *Tree[K, D, C]) ParallelDo(workers int, f func(k K, d D)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, t.Size()/parallelCutoff)
+	if workers <= 1 {
+		t.root.doAll2Flat(func(k K, d D) bool {
+			f(k, d)
+			return true
+		})
+		return
+	}
+	var wg sync.WaitGroup
+	for _, r := range t.SplitPoints(workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.root.doRangeFlat(r.Lo, r.Hi, t.cmp(), func(k K, d D) bool {
+				f(k, d)
+				return true
+			})
+		}()
+	}
+	wg.Wait()
+}
+
+// Map returns a tree with the same keys as t, and data f(k, d)
+// for each key k and data d of t.  The result has the same shape
+// as t, so it is built in O(n) time without comparing keys.
+func Map[K any, D any, E any, C Comparer[K]](t *Tree[K, D, C], f func(k K, d D) E) *Tree[K, E, C] {
+	return mapTree(t, f, nil)
+}
+
+// ParallelMap is like Map, but uses up to workers goroutines, or
+// GOMAXPROCS if workers is not positive, calling f concurrently.
+func ParallelMap[K any, D any, E any, C Comparer[K]](workers int, t *Tree[K, D, C], f func(k K, d D) E) *Tree[K, E, C] {
+	return mapTree(t, f, newForker(workers))
+}
+
+func mapTree[K any, D any, E any, C Comparer[K]](t *Tree[K, D, C], f func(k K, d D) E, p *forker) *Tree[K, E, C] {
+	u := &Tree[K, E, C]{root: mapNode(t.root, f, p), size: t.size, order: t.order}
+	return u.check()
+}
+
+func mapNode[K any, D any, E any](t *node[K, D], f func(k K, d D) E, p *forker) *node[K, E] {
+	if t == nil {
+		return nil
+	}
+	u := &node[K, E]{key: t.key, size_: t.size_, height_: t.height_}
+	if p.forks(t.size()) {
+		u.left, u.right = fork(p,
+			func() *node[K, E] { return mapNode(t.left, f, p) },
+			func() *node[K, E] { return mapNode(t.right, f, p) })
+	} else {
+		u.left, u.right = mapNode(t.left, f, p), mapNode(t.right, f, p)
+	}
+	u.data = f(t.key, t.data)
+	return u
+}
+
+// ParallelUnion is like Union, but uses up to workers goroutines,
+// or GOMAXPROCS if workers is not positive.  If f is not nil, it
+// may be called concurrently.
+func ParallelUnion[K any, D any, C Comparer[K]](workers int, t, u *Tree[K, D, C], f func(x, y D) (D, bool)) *Tree[K, D, C] {
+	if t.Size() == 0 {
+		return u
+	}
+	if u.Size() == 0 {
+		return t
+	}
+	if f == nil && t.Size() < u.Size() {
+		t, u = u, t
+	}
+	r := union(t.root, u.root, f, t.cmp(), newForker(workers))
+	return t.withRoot(r)
+}
+
+// ParallelIntersection is like Intersection, but uses up to workers
+// goroutines, or GOMAXPROCS if workers is not positive.  If f is not
+// nil, it may be called concurrently.
+func ParallelIntersection[K any, D any, C Comparer[K]](workers int, t, u *Tree[K, D, C], f func(x, y D) (D, bool)) *Tree[K, D, C] {
+	if t.Size() == 0 || u.Size() == 0 {
+		return t.empty()
+	}
+	if f == nil && t.Size() > u.Size() {
+		t, u = u, t
+	}
+	r := intersection(t.root, u.root, f, t.cmp(), newForker(workers))
+	return t.withRoot(r)
+}
+
+// ParallelDifference is like Difference, but uses up to workers
+// goroutines, or GOMAXPROCS if workers is not positive.  If f is not
+// nil, it may be called concurrently.
+func ParallelDifference[K any, D any, C Comparer[K]](workers int, t, u *Tree[K, D, C], f func(x, y D) (D, bool)) *Tree[K, D, C] {
+	if t.Size() == 0 {
+		return t.empty()
+	}
+	if u.Size() == 0 {
+		return t
+	}
+	r := difference(t.root, u.root, f, t.cmp(), newForker(workers))
+	return t.withRoot(r)
+}
+
+// A forker runs pairs of functions, in parallel when it can.
+// Its tokens limit how many goroutines it has started at once.
+type forker struct {
+	tokens chan struct{}
+}
+
+// newForker returns a forker that runs up to workers goroutines at
+// once, counting the caller's, or GOMAXPROCS if workers is not positive.
+func newForker(workers int) *forker {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &forker{tokens: make(chan struct{}, workers-1)}
+}
+
+// forks reports whether work on subtrees of combined size n
+// should be split between goroutines.  A nil forker never forks.
+func (p *forker) forks(n int) bool {
+	return p != nil && n >= parallelCutoff
+}
+
+// fork returns a() and b().  If p has a token to spare, a runs in a
+// new goroutine while b runs in this one; otherwise they run one after
+// the other.  If a panics, fork panics with the same value once b has
+// returned, as it would if a ran in this goroutine.  Callers check
+// forks first, since the closures passed to fork are allocated.
+func fork[T any](p *forker, a, b func() T) (T, T) {
+	select {
+	case p.tokens <- struct{}{}:
+		var x T
+		var panicked any
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			defer func() {
+				panicked = recover()
+				<-p.tokens
+			}()
+			x = a()
+		}()
+		y := b()
+		<-done
+		if panicked != nil {
+			panic(panicked)
+		}
+		return x, y
+	default:
+	}
+	return a(), b()
+}
@@ -0,0 +1,208 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSplitPoints(t *testing.T) {
+	for _, n := range []int{0, 1, 5, 100, 1001} {
+		tr := makeTree(0, n, 1)
+		for _, k := range []int{0, 1, 3, 7, 2000} {
+			ranges := tr.SplitPoints(k)
+			if want := min(max(k, 1), n); len(ranges) != want {
+				t.Errorf("n=%d: SplitPoints(%d) returned %d ranges, want %d", n, k, len(ranges), want)
+			}
+			next := 0
+			for i, r := range ranges {
+				c := tr.CountRange(r.Lo, r.Hi)
+				if c < n/len(ranges) || c > n/len(ranges)+1 {
+					t.Errorf("n=%d: SplitPoints(%d)[%d] has %d elements", n, k, i, c)
+				}
+				for key := range tr.Range(r.Lo, r.Hi) {
+					if int(key) != next {
+						t.Fatalf("n=%d: SplitPoints(%d)[%d] has key %d, want %d", n, k, i, key, next)
+					}
+					next++
+				}
+			}
+			if next != n {
+				t.Errorf("n=%d: SplitPoints(%d) covered %d keys", n, k, next)
+			}
+		}
+	}
+}
+
+func TestParallelDo(t *testing.T) {
+	const n = 20 * parallelCutoff
+	tr := makeTree(0, n, 1)
+	for _, workers := range []int{0, 1, 3, 8} {
+		var seen [n]atomic.Int32
+		var sum atomic.Int64
+		tr.ParallelDo(workers, func(k Int, d int) {
+			seen[k].Add(1)
+			sum.Add(int64(d))
+		})
+		for k := range seen {
+			if c := seen[k].Load(); c != 1 {
+				t.Fatalf("workers=%d: key %d visited %d times", workers, k, c)
+			}
+		}
+		if want := int64(10 * n * (n - 1) / 2); sum.Load() != want {
+			t.Errorf("workers=%d: sum of data %d, want %d", workers, sum.Load(), want)
+		}
+	}
+}
+
+func TestParallelMap(t *testing.T) {
+	for _, n := range []int{0, 10, 10 * parallelCutoff} {
+		tr := makeTree(0, n, 1)
+		var mu sync.Mutex
+		calls := 0
+		m := ParallelMap(4, tr, func(k Int, d int) string {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			return fmt.Sprint(int(k) + d)
+		})
+		if calls != n || m.Size() != n {
+			t.Errorf("n=%d: %d calls, map has size %d", n, calls, m.Size())
+		}
+		if err := m.Validate(); err != nil {
+			t.Errorf("n=%d: %v", n, err)
+		}
+		if !Equals(m, Map(tr, func(k Int, d int) string { return fmt.Sprint(11 * int(k)) })) {
+			t.Errorf("n=%d: ParallelMap differs from Map", n)
+		}
+	}
+}
+
+func TestParallelSetOps(t *testing.T) {
+	fs := []func(x, y int) (int, bool){nil, keepSum, dropOdd}
+	a := makeTree(0, 20*parallelCutoff, 2)
+	b := makeTree(1000, 30*parallelCutoff, 3)
+	// share some structure between a and c
+	c := a.Copy()
+	for k := range 500 {
+		c.Insert(Int(97*k), -k)
+	}
+	pairs := [][2]*T[Int, int]{{a, b}, {b, a}, {a, c}, {c, a}, {a, a}, {a, &T[Int, int]{}}}
+	for _, p := range pairs {
+		x, y := p[0], p[1]
+		for i, f := range fs {
+			check := func(name string, got, want *T[Int, int]) {
+				t.Helper()
+				if err := got.Validate(); err != nil {
+					t.Fatalf("%s(%d, %d, f%d): %v", name, x.Size(), y.Size(), i, err)
+				}
+				if got.Size() != want.Size() || !Equals(got, want) {
+					t.Fatalf("%s(%d, %d, f%d) differs from the sequential result", name, x.Size(), y.Size(), i)
+				}
+			}
+			check("ParallelUnion", ParallelUnion(4, x, y, f), Union(x, y, f))
+			check("ParallelIntersection", ParallelIntersection(4, x, y, f), Intersection(x, y, f))
+			check("ParallelDifference", ParallelDifference(4, x, y, f), Difference(x, y, f))
+		}
+	}
+	if u := ParallelUnion(4, a, a, nil); u.root != a.root {
+		t.Errorf("ParallelUnion of a tree with itself was not shared")
+	}
+	// Where one side is empty, the other is shared, not rebuilt.
+	d := &T[Int, int]{}
+	d.Insert(1<<30, 0)
+	if u := ParallelUnion(4, a, d, nil); u.root.left != a.root.left {
+		t.Errorf("ParallelUnion did not share an untouched subtree")
+	}
+}
+
+// The parallel benchmarks use GOMAXPROCS workers; run them with
+// -cpu 1,2,4,8 to see how they scale.
+
+func BenchmarkParallelUnion(b *testing.B) {
+	benchmarkSetOp(b, func(x, y *T[Int, int], f func(x, y int) (int, bool)) *T[Int, int] {
+		return ParallelUnion(0, x, y, f)
+	})
+}
+
+func BenchmarkParallelIntersection(b *testing.B) {
+	benchmarkSetOp(b, func(x, y *T[Int, int], f func(x, y int) (int, bool)) *T[Int, int] {
+		return ParallelIntersection(0, x, y, f)
+	})
+}
+
+func BenchmarkParallelDifference(b *testing.B) {
+	benchmarkSetOp(b, func(x, y *T[Int, int], f func(x, y int) (int, bool)) *T[Int, int] {
+		return ParallelDifference(0, x, y, f)
+	})
+}
+
+func BenchmarkParallelDo(b *testing.B) {
+	tr := makeTree(0, 1<<20, 1)
+	b.ResetTimer()
+	var sum atomic.Int64
+	for i := 0; i < b.N; i++ {
+		tr.ParallelDo(0, func(k Int, d int) {
+			if d < 0 {
+				sum.Add(1)
+			}
+		})
+	}
+}
+
+func BenchmarkMap(b *testing.B) {
+	tr := makeTree(0, 1<<20, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Map(tr, func(k Int, d int) int { return d + 1 })
+	}
+}
+
+func BenchmarkParallelMap(b *testing.B) {
+	tr := makeTree(0, 1<<20, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ParallelMap(0, tr, func(k Int, d int) int { return d + 1 })
+	}
+}
+
+// TestParallelPanic checks that a panic in a function called by a
+// forked goroutine reaches the caller, as it does sequentially.
+func TestParallelPanic(t *testing.T) {
+	a := makeTree(0, 20*parallelCutoff, 1)
+	b := makeTree(0, 20*parallelCutoff, 3)
+	expectPanic := func(name string, op func()) {
+		t.Helper()
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("%s panicked with %v, want boom", name, r)
+			}
+		}()
+		op()
+	}
+	// The last key is reached by the right half of the first fork,
+	// and the first by the left half, which runs in another goroutine.
+	for _, bad := range []int{0, 10 * (20*parallelCutoff - 1)} {
+		expectPanic("ParallelUnion", func() {
+			ParallelUnion(4, a, b, func(x, y int) (int, bool) {
+				if x == bad {
+					panic("boom")
+				}
+				return x, true
+			})
+		})
+		expectPanic("ParallelMap", func() {
+			ParallelMap(4, a, func(k Int, d int) int {
+				if d == bad {
+					panic("boom")
+				}
+				return d
+			})
+		})
+	}
+}
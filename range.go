@@ -0,0 +1,145 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"iter"
+)
+
+type boundKind int8
+
+const (
+	unbounded boundKind = iota
+	inclusive
+	exclusive
+)
+
+// A Bound is one end of a range of keys; it is either inclusive,
+// exclusive, or unbounded (open).  The zero Bound is unbounded.
+type Bound[K any] struct {
+	key  K
+	kind boundKind
+}
+
+// Inclusive returns a bound that includes k.
+func Inclusive[K any](k K) Bound[K] {
+	return Bound[K]{key: k, kind: inclusive}
+}
+
+// Exclusive returns a bound that excludes k.
+func Exclusive[K any](k K) Bound[K] {
+	return Bound[K]{key: k, kind: exclusive}
+}
+
+// Unbounded returns an open bound, one that does not limit the range.
+func Unbounded[K any]() Bound[K] {
+	return Bound[K]{}
+}
+
+// Range returns an iterator over the key and data pairs in t with keys
+// between lo and hi, ordered from smallest to largest.  The iterator
+// seeks directly to lo, so the cost is O(log n) plus the number
+// of elements yielded.
+func (t *Tree[K, D, C]) Range(lo, hi Bound[K]) iter.Seq2[K, D] {
+	return func(yield func(k K, d D) bool) {
+		t.root.doRangeFlat(lo, hi, t.cmp(), yield)
+	}
+}
+
+// RangeBackward is like Range, but yields keys from largest to smallest.
+func (t *Tree[K, D, C]) RangeBackward(lo, hi Bound[K]) iter.Seq2[K, D] {
+	return func(yield func(k K, d D) bool) {
+		t.root.doRangeFlatBackward(lo, hi, t.cmp(), yield)
+	}
+}
+
+// From returns an iterator over the key and data pairs in t with keys
+// greater than or equal to k, ordered from smallest to largest.
+func (t *Tree[K, D, C]) From(k K) iter.Seq2[K, D] {
+	return t.Range(Inclusive(k), Unbounded[K]())
+}
+
+// Until returns an iterator over the key and data pairs in t with keys
+// less than k, ordered from smallest to largest.
+func (t *Tree[K, D, C]) Until(k K) iter.Seq2[K, D] {
+	return t.Range(Unbounded[K](), Exclusive(k))
+}
+
+// belowHi returns true iff k does not exceed the upper bound hi.
+func belowHi[K any](hi Bound[K], k K, compare func(a, b K) int) bool {
+	switch hi.kind {
+	case inclusive:
+		return compare(k, hi.key) <= 0
+	case exclusive:
+		return compare(k, hi.key) < 0
+	}
+	return true
+}
+
+// aboveLo returns true iff k is not less than the lower bound lo.
+func aboveLo[K any](lo Bound[K], k K, compare func(a, b K) int) bool {
+	switch lo.kind {
+	case inclusive:
+		return compare(k, lo.key) >= 0
+	case exclusive:
+		return compare(k, lo.key) > 0
+	}
+	return true
+}
+
+func (n *node[K, D]) doRangeFlat(lo, hi Bound[K], compare func(a, b K) int, yield func(k K, d D) bool) {
+	var stack nodeStack[K, D]
+	var top = 0
+
+	if lo.kind == unbounded {
+		for ; n != nil; n = n.left {
+			top = stack.push(top, n)
+		}
+	} else {
+		_, top = n.lubStack(lo.key, lo.kind == inclusive, compare, &stack, top)
+	}
+
+	doStackFlat(&stack, top, hi, compare, yield)
+}
+
+// doStackFlat continues an ascending traversal from a stack
+// of pending nodes such as the one built by lubStack, stopping
+// when a key exceeds hi.
+func doStackFlat[K any, D any](stack *nodeStack[K, D], top int, hi Bound[K], compare func(a, b K) int, yield func(k K, d D) bool) {
+	for top > 0 {
+		top--
+		n := stack[top]
+		if !belowHi(hi, n.key, compare) || !yield(n.key, n.data) {
+			return
+		}
+		for n = n.right; n != nil; n = n.left {
+			top = stack.push(top, n)
+		}
+	}
+}
+
+func (n *node[K, D]) doRangeFlatBackward(lo, hi Bound[K], compare func(a, b K) int, yield func(k K, d D) bool) {
+	var stack nodeStack[K, D]
+	var top = 0
+
+	if hi.kind == unbounded {
+		for ; n != nil; n = n.right {
+			top = stack.push(top, n)
+		}
+	} else {
+		_, top = n.glbStack(hi.key, hi.kind == inclusive, compare, &stack, top)
+	}
+
+	for top > 0 {
+		top--
+		n = stack[top]
+		if !aboveLo(lo, n.key, compare) || !yield(n.key, n.data) {
+			return
+		}
+		for n = n.left; n != nil; n = n.right {
+			top = stack.push(top, n)
+		}
+	}
+}
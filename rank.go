@@ -0,0 +1,121 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Rank returns the number of keys in t that are less than x;
+// if x is in t, this is its position in key order.
+func (t *Tree[K, D, C]) Rank(x K) int {
+	return t.root.rank(x, false, t.cmp())
+}
+
+// Select returns the key and data at position i in key order
+// (counting from zero) and true, or zero values and false if
+// i is out of range.
+func (t *Tree[K, D, C]) Select(i int) (K, D, bool) {
+	if i < 0 || i >= t.root.size() {
+		return zero[K](), zero[D](), false
+	}
+	return t.root.selectNode(i).keyDataAndOk()
+}
+
+// At returns the key and data at position i in key order,
+// counting from zero.  It panics if i is out of range.
+func (t *Tree[K, D, C]) At(i int) (K, D) {
+	k, d, ok := t.Select(i)
+	if !ok {
+		panic(fmt.Sprintf("index %d out of range for tree of size %d", i, t.Size()))
+	}
+	return k, d
+}
+
+// CountRange returns the number of keys in t between lo and hi,
+// in O(log n) time.
+func (t *Tree[K, D, C]) CountRange(lo, hi Bound[K]) int {
+	n := t.root.size()
+	if hi.kind != unbounded {
+		n = t.root.rank(hi.key, hi.kind == inclusive, t.cmp())
+	}
+	if lo.kind != unbounded {
+		n -= t.root.rank(lo.key, lo.kind == exclusive, t.cmp())
+	}
+	return max(n, 0)
+}
+
+// Skip returns an iterator over the key and data pairs in t, ordered
+// from smallest to largest, that starts at position n.  Finding the
+// starting position costs O(log n).
+func (t *Tree[K, D, C]) Skip(n int) iter.Seq2[K, D] {
+	return func(yield func(k K, d D) bool) {
+		if n < 0 {
+			n = 0
+		}
+		var stack nodeStack[K, D]
+		top := t.root.selectStack(n, &stack, 0)
+		doStackFlat(&stack, top, Unbounded[K](), t.cmp(), yield)
+	}
+}
+
+// rank returns the number of keys in t less than key,
+// or less than or equal to key if allow_eq.
+func (t *node[K, D]) rank(key K, allow_eq bool, compare func(a, b K) int) int {
+	r := 0
+	for t != nil {
+		cmp := compare(key, t.key)
+		if cmp < 0 {
+			t = t.left
+		} else if cmp > 0 {
+			r += t.left.size() + 1
+			t = t.right
+		} else {
+			r += t.left.size()
+			if allow_eq {
+				r++
+			}
+			break
+		}
+	}
+	return r
+}
+
+// selectNode returns the node at position i in t, or nil
+// if there is no such node.
+func (t *node[K, D]) selectNode(i int) *node[K, D] {
+	for t != nil {
+		ls := t.left.size()
+		if i < ls {
+			t = t.left
+		} else if i > ls {
+			i -= ls + 1
+			t = t.right
+		} else {
+			return t
+		}
+	}
+	return nil
+}
+
+// selectStack is to selectNode as lubStack is to lub; it pushes
+// the nodes at and after position i that are not in the right
+// subtree of the node at i onto stack, and returns the new top.
+func (t *node[K, D]) selectStack(i int, stack *nodeStack[K, D], top int) int {
+	for t != nil {
+		ls := t.left.size()
+		if i < ls {
+			top = stack.push(top, t)
+			t = t.left
+		} else if i > ls {
+			i -= ls + 1
+			t = t.right
+		} else {
+			return stack.push(top, t)
+		}
+	}
+	return top
+}
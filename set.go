@@ -0,0 +1,252 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// A TreeSet is a persistent set of keys K ordered by the comparer C.
+// It uses the same AVL nodes as Tree, with a zero-sized payload.
+// Like Tree, most code will use one of its aliases, Set, OrderedSet,
+// or FuncSet.
+type TreeSet[K any, C Comparer[K]] struct {
+	t Tree[K, struct{}, C]
+}
+
+// Set is a TreeSet whose keys are ordered by their Compare method.
+// The zero Set is empty and ready to use.
+type Set[K Comparable[K]] = TreeSet[K, MethodCompare[K]]
+
+// OrderedSet is a TreeSet whose keys are ordered by cmp.Compare.
+// The zero OrderedSet is empty and ready to use.
+type OrderedSet[K cmp.Ordered] = TreeSet[K, OrderedCompare[K]]
+
+// FuncSet is a TreeSet whose keys are ordered by a comparison function.
+// Use NewFuncSet to create one.
+type FuncSet[K any] = TreeSet[K, FuncCompare[K]]
+
+// NewFuncSet returns an empty FuncSet ordered by cmp.
+func NewFuncSet[K any](cmp func(a, b K) int) *FuncSet[K] {
+	return &FuncSet[K]{t: Tree[K, struct{}, FuncCompare[K]]{order: FuncCompare[K]{cmp}}}
+}
+
+// CollectSet returns a Set containing the keys of seq.
+func CollectSet[K Comparable[K]](seq iter.Seq[K]) *Set[K] {
+	return collectSet(&Set[K]{}, seq)
+}
+
+// CollectOrderedSet returns an OrderedSet containing the keys of seq.
+func CollectOrderedSet[K cmp.Ordered](seq iter.Seq[K]) *OrderedSet[K] {
+	return collectSet(&OrderedSet[K]{}, seq)
+}
+
+// CollectFuncSet returns a FuncSet ordered by cmp containing the keys of seq.
+func CollectFuncSet[K any](seq iter.Seq[K], cmp func(a, b K) int) *FuncSet[K] {
+	return collectSet(NewFuncSet(cmp), seq)
+}
+
+func collectSet[K any, C Comparer[K]](s *TreeSet[K, C], seq iter.Seq[K]) *TreeSet[K, C] {
+	collect(&s.t, func(yield func(K, struct{}) bool) {
+		for k := range seq {
+			if !yield(k, struct{}{}) {
+				return
+			}
+		}
+	})
+	return s
+}
+
+func (s *TreeSet[K, C]) with(t *Tree[K, struct{}, C]) *TreeSet[K, C] {
+	return &TreeSet[K, C]{t: *t}
+}
+
+// Add adds x to s, and returns true iff x was not already in s.
+func (s *TreeSet[K, C]) Add(x K) bool {
+	n := s.t.Size()
+	s.t.Insert(x, struct{}{})
+	return s.t.Size() != n
+}
+
+// Remove removes x from s, and returns true iff x was in s.
+func (s *TreeSet[K, C]) Remove(x K) bool {
+	_, ok := s.t.DeleteOk(x)
+	return ok
+}
+
+// Has returns true iff x is in s.
+func (s *TreeSet[K, C]) Has(x K) bool {
+	return s.t.Contains(x)
+}
+
+func (s *TreeSet[K, C]) Size() int {
+	return s.t.Size()
+}
+
+func (s *TreeSet[K, C]) IsEmpty() bool {
+	return s.t.IsEmpty()
+}
+
+func (s *TreeSet[K, C]) Copy() *TreeSet[K, C] {
+	return s.with(&s.t)
+}
+
+// Min returns the least element of s, and false if s is empty.
+func (s *TreeSet[K, C]) Min() (K, bool) {
+	k, _, ok := s.t.MinOk()
+	return k, ok
+}
+
+// Max returns the greatest element of s, and false if s is empty.
+func (s *TreeSet[K, C]) Max() (K, bool) {
+	k, _, ok := s.t.MaxOk()
+	return k, ok
+}
+
+// All returns an iterator over the elements of s, from least to greatest.
+func (s *TreeSet[K, C]) All() iter.Seq[K] {
+	return s.t.Keys()
+}
+
+// Backward returns an iterator over the elements of s, from greatest to least.
+func (s *TreeSet[K, C]) Backward() iter.Seq[K] {
+	return func(yield func(k K) bool) {
+		for k := range s.t.Backward() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Range returns an iterator over the elements of s between lo and hi,
+// from least to greatest.
+func (s *TreeSet[K, C]) Range(lo, hi Bound[K]) iter.Seq[K] {
+	return func(yield func(k K) bool) {
+		for k := range s.t.Range(lo, hi) {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Union returns the elements in s or u.
+func (s *TreeSet[K, C]) Union(u *TreeSet[K, C]) *TreeSet[K, C] {
+	return s.with(Union(&s.t, &u.t, nil))
+}
+
+// Intersect returns the elements in both s and u.
+func (s *TreeSet[K, C]) Intersect(u *TreeSet[K, C]) *TreeSet[K, C] {
+	return s.with(Intersection(&s.t, &u.t, nil))
+}
+
+// Minus returns the elements in s but not in u.
+func (s *TreeSet[K, C]) Minus(u *TreeSet[K, C]) *TreeSet[K, C] {
+	return s.with(Difference(&s.t, &u.t, nil))
+}
+
+// SymDiff returns the elements in exactly one of s and u.
+func (s *TreeSet[K, C]) SymDiff(u *TreeSet[K, C]) *TreeSet[K, C] {
+	return s.with(SymmetricDifference(&s.t, &u.t))
+}
+
+// IsSubset returns true iff every element of s is in u.
+func (s *TreeSet[K, C]) IsSubset(u *TreeSet[K, C]) bool {
+	if s.Size() > u.Size() {
+		return false
+	}
+	return subset(s.t.root, u.t.root, s.t.cmp())
+}
+
+// IsSuperset returns true iff every element of u is in s.
+func (s *TreeSet[K, C]) IsSuperset(u *TreeSet[K, C]) bool {
+	return u.IsSubset(s)
+}
+
+// Disjoint returns true iff s and u have no elements in common.
+func (s *TreeSet[K, C]) Disjoint(u *TreeSet[K, C]) bool {
+	if s.Size() > u.Size() {
+		s, u = u, s
+	}
+	return disjoint(s.t.root, u.t.root, s.t.cmp())
+}
+
+// Equal returns true iff s and u contain the same elements.
+func (s *TreeSet[K, C]) Equal(u *TreeSet[K, C]) bool {
+	return Equals(&s.t, &u.t)
+}
+
+func (s *TreeSet[K, C]) String() string {
+	var b strings.Builder
+	b.WriteString("{")
+	for k := range s.All() {
+		if b.Len() > 1 {
+			b.WriteString(", ")
+		}
+		fmt.Fprint(&b, k)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+// subset returns true iff every key of t is a key of u.
+// Subtrees shared by t and u are not examined.
+func subset[K any, D any](t, u *node[K, D], compare func(a, b K) int) bool {
+	return subsetIn(t, u, Unbounded[K](), Unbounded[K](), compare)
+}
+
+// subsetIn is subset for a t whose keys are between lo and hi.  Like
+// the join-based operations, it narrows u to the keys that t could
+// contain, so that a subtree shared by t and u is met at the same
+// point in both; unlike them, it does so without copying, by
+// descending to the highest node of u that is between lo and hi.
+func subsetIn[K any, D any](t, u *node[K, D], lo, hi Bound[K], compare func(a, b K) int) bool {
+	u = u.within(lo, hi, compare)
+	if t == nil || t == u {
+		return true
+	}
+	return u.find(t.key, compare) != nil &&
+		subsetIn(t.left, u, lo, Exclusive(t.key), compare) &&
+		subsetIn(t.right, u, Exclusive(t.key), hi, compare)
+}
+
+// disjoint returns true iff t and u have no keys in common.
+// Subtrees shared by t and u are not examined.
+func disjoint[K any, D any](t, u *node[K, D], compare func(a, b K) int) bool {
+	return disjointIn(t, u, Unbounded[K](), Unbounded[K](), compare)
+}
+
+// disjointIn is to disjoint as subsetIn is to subset.
+func disjointIn[K any, D any](t, u *node[K, D], lo, hi Bound[K], compare func(a, b K) int) bool {
+	u = u.within(lo, hi, compare)
+	if t == nil || u == nil {
+		return true
+	}
+	if t == u {
+		return false
+	}
+	return u.find(t.key, compare) == nil &&
+		disjointIn(t.left, u, lo, Exclusive(t.key), compare) &&
+		disjointIn(t.right, u, Exclusive(t.key), hi, compare)
+}
+
+// within returns the highest node of t whose key is between lo and
+// hi; its subtree holds every key of t between them.
+func (t *node[K, D]) within(lo, hi Bound[K], compare func(a, b K) int) *node[K, D] {
+	for t != nil {
+		if !aboveLo(lo, t.key, compare) {
+			t = t.right
+		} else if !belowHi(hi, t.key, compare) {
+			t = t.left
+		} else {
+			break
+		}
+	}
+	return t
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"slices"
+	"testing"
+	"unsafe"
+)
+
+func intsFrom(lo, hi, step int) func(yield func(Int) bool) {
+	return func(yield func(Int) bool) {
+		for i := lo; i < hi; i += step {
+			if !yield(Int(i)) {
+				return
+			}
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	if s := unsafe.Sizeof(node[Int, struct{}]{}); s > unsafe.Sizeof(node[Int, int]{})-8 {
+		t.Errorf("set node has a data payload; size %d", s)
+	}
+	evens := CollectSet(intsFrom(0, 100, 2))
+	threes := CollectSet(intsFrom(0, 100, 3))
+	if evens.Size() != 50 || !evens.Has(42) || evens.Has(43) {
+		t.Fatalf("evens = %v", evens)
+	}
+	if n := evens.Union(threes).Size(); n != 50+34-17 {
+		t.Errorf("Union size = %d", n)
+	}
+	sixes := evens.Intersect(threes)
+	if !sixes.Equal(CollectSet(intsFrom(0, 100, 6))) {
+		t.Errorf("Intersect = %v", sixes)
+	}
+	if !sixes.IsSubset(evens) || !evens.IsSuperset(sixes) || evens.IsSubset(sixes) {
+		t.Errorf("subset predicates are wrong")
+	}
+	if !evens.IsSubset(evens.Copy()) {
+		t.Errorf("a set is not a subset of its copy")
+	}
+	if m := evens.Minus(threes); m.Has(6) || !m.Has(4) || m.Size() != 33 {
+		t.Errorf("Minus = %v", m)
+	}
+	if d := evens.SymDiff(threes); d.Has(6) || !d.Has(4) || !d.Has(3) || d.Size() != 50+34-2*17 {
+		t.Errorf("SymDiff = %v", d)
+	}
+	odds := CollectSet(intsFrom(1, 100, 2))
+	if !evens.Disjoint(odds) || evens.Disjoint(threes) {
+		t.Errorf("Disjoint is wrong")
+	}
+
+	s := &Set[Int]{}
+	if !s.Add(3) || s.Add(3) || !s.Add(1) || !s.Remove(3) || s.Remove(3) {
+		t.Errorf("Add/Remove results are wrong")
+	}
+	if lo, ok := evens.Min(); !ok || lo != 0 {
+		t.Errorf("Min = %d, %v", lo, ok)
+	}
+	if hi, ok := evens.Max(); !ok || hi != 98 {
+		t.Errorf("Max = %d, %v", hi, ok)
+	}
+	if got := slices.Collect(evens.Range(Exclusive[Int](90), Unbounded[Int]())); !slices.Equal(got, []Int{92, 94, 96, 98}) {
+		t.Errorf("Range = %v", got)
+	}
+	if got := slices.Collect(odds.Backward()); got[0] != 99 || len(got) != 50 {
+		t.Errorf("Backward = %v", got)
+	}
+	if got := s.String(); got != "{1}" {
+		t.Errorf("String = %s", got)
+	}
+
+	o := CollectOrderedSet(slices.Values([]string{"b", "a", "c", "a"}))
+	if got := slices.Collect(o.All()); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("OrderedSet = %v", got)
+	}
+}
+
+// TestSetSharing checks that IsSubset and Disjoint skip the subtrees
+// that two versions of a set share, and still get the right answers.
+func TestSetSharing(t *testing.T) {
+	compares := 0
+	s := CollectFuncSet(intsFrom(0, 1<<12, 1), func(a, b Int) int {
+		compares++
+		return a.Compare(b)
+	})
+	u := s.Copy()
+	u.Add(1 << 20)
+	u.Add(-1)
+	compares = 0
+	if !s.IsSubset(u) || u.IsSubset(s) {
+		t.Errorf("IsSubset of a set and an extended copy is wrong")
+	}
+	if compares > 1000 {
+		t.Errorf("IsSubset of sets sharing most nodes made %d comparisons", compares)
+	}
+	v := s.Copy()
+	v.Remove(100)
+	w := CollectFuncSet(intsFrom(100, 101, 1), s.t.order.cmp)
+	if !v.Disjoint(w) || s.Disjoint(w) || s.Disjoint(v) {
+		t.Errorf("Disjoint is wrong")
+	}
+}
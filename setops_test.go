@@ -0,0 +1,272 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"fmt"
+	"testing"
+)
+
+// The ...ByInsert functions are the original set operations, which
+// iterate over one tree and Find/Insert/Delete in a copy of the other.
+// They serve as a reference for tests and a baseline for benchmarks.
+func intersectionByInsert[K Comparable[K], D any](t, u *T[K, D], f func(x, y D) (D, bool)) *T[K, D] {
+	if t.Size() == 0 || u.Size() == 0 {
+		return &T[K, D]{}
+	}
+
+	// For faster execution and less allocation, prefer t smaller, iterate over t.
+	if t.Size() <= u.Size() {
+		v := t.Copy()
+		for it := t.ToIter(); it.More(); {
+			k, d := it.Next()
+			e, ok := u.Get(k)
+			if !ok {
+				v.Delete(k)
+				continue
+			}
+			if f == nil {
+				continue
+			}
+			if c, ok := f(d, e); ok {
+				v.Insert(k, c)
+			} else {
+				v.Delete(k)
+			}
+		}
+		return v
+	}
+	v := u.Copy()
+	for it := u.ToIter(); it.More(); {
+		k, e := it.Next()
+		d, ok := t.Get(k)
+		if !ok {
+			v.Delete(k)
+			continue
+		}
+		if f == nil {
+			continue
+		}
+		if c, ok := f(d, e); ok {
+			v.Insert(k, c)
+		} else {
+			v.Delete(k)
+		}
+	}
+
+	return v
+}
+
+func unionByInsert[K Comparable[K], D any](t, u *T[K, D], f func(x, y D) (D, bool)) *T[K, D] {
+	if t.Size() == 0 {
+		return u
+	}
+	if u.Size() == 0 {
+		return t
+	}
+
+	if t.Size() >= u.Size() {
+		v := t.Copy()
+		for it := u.ToIter(); it.More(); {
+			k, e := it.Next()
+			d, ok := t.Get(k)
+			if !ok {
+				v.Insert(k, e)
+				continue
+			}
+			if f == nil {
+				continue
+			}
+			if c, ok := f(d, e); ok {
+				v.Insert(k, c)
+			} else {
+				v.Delete(k)
+			}
+		}
+		return v
+	}
+
+	v := u.Copy()
+	for it := t.ToIter(); it.More(); {
+		k, d := it.Next()
+		e, ok := u.Get(k)
+		if !ok {
+			v.Insert(k, d)
+			continue
+		}
+		if f == nil {
+			continue
+		}
+		if c, ok := f(d, e); ok {
+			v.Insert(k, c)
+		} else {
+			v.Delete(k)
+		}
+	}
+	return v
+}
+
+func differenceByInsert[K Comparable[K], D any](t, u *T[K, D], f func(x, y D) (D, bool)) *T[K, D] {
+	if t.Size() == 0 {
+		return &T[K, D]{}
+	}
+	if u.Size() == 0 {
+		return t
+	}
+	v := t.Copy()
+	for it := t.ToIter(); it.More(); {
+		k, d := it.Next()
+		e, ok := u.Get(k)
+		if !ok {
+			continue
+		}
+		if f == nil {
+			v.Delete(k)
+			continue
+		}
+		if c, ok := f(d, e); ok {
+			v.Insert(k, c)
+		} else {
+			v.Delete(k)
+		}
+	}
+	return v
+}
+
+func keepSum(x, y int) (int, bool) { return x + y, true }
+
+func dropOdd(x, y int) (int, bool) { return x - y, (x+y)%4 != 0 }
+
+func TestSetOps(t *testing.T) {
+	fs := []func(x, y int) (int, bool){nil, keepSum, dropOdd}
+	for _, sizes := range [][4]int{{0, 50, 1, 10}, {0, 300, 3, 2}, {100, 120, 1, 5}, {0, 1000, 1, 7}, {0, 10, 1, 1}} {
+		a := makeTree(0, sizes[1], sizes[3])
+		b := makeTree(sizes[0], sizes[2]*sizes[1], sizes[2])
+		// share some structure between a and b
+		c := a.Copy()
+		c.Insert(-1, -1)
+		pairs := [][2]*T[Int, int]{{a, b}, {b, a}, {a, c}, {c, a}, {a, a}}
+		for _, p := range pairs {
+			x, y := p[0], p[1]
+			for i, f := range fs {
+				check := func(name string, got, want *T[Int, int]) {
+					t.Helper()
+					checkSizes(t, got.root)
+					if got.Size() != want.Size() || got.String() != want.String() {
+						t.Fatalf("%s(%d, %d, f%d) = %v, want %v", name, x.Size(), y.Size(), i, got, want)
+					}
+					if got.root.height() > 2*bits(got.Size())+1 {
+						t.Fatalf("%s result is unbalanced, height %d for size %d", name, got.root.height(), got.Size())
+					}
+				}
+				check("Union", Union(x, y, f), unionByInsert(x, y, f))
+				check("Intersection", Intersection(x, y, f), intersectionByInsert(x, y, f))
+				check("Difference", Difference(x, y, f), differenceByInsert(x, y, f))
+			}
+			want := unionByInsert(differenceByInsert(x, y, nil), differenceByInsert(y, x, nil), nil)
+			if got := SymmetricDifference(x, y); got.String() != want.String() || got.Size() != want.Size() {
+				t.Fatalf("SymmetricDifference = %v, want %v", got, want)
+			}
+		}
+	}
+}
+
+func TestSetOpsSharing(t *testing.T) {
+	a := makeTree(0, 1000, 1)
+	b := a.Copy()
+	b.Insert(5000, 5)
+	u := Union(a, b, nil)
+	if u.root.left != a.root.left {
+		t.Errorf("Union did not share untouched left subtree")
+	}
+	if d := Difference(b, a, nil); d.Size() != 1 {
+		t.Errorf("Difference of near-identical trees = %v", d)
+	}
+	if i := Intersection(a, a, nil); i.root != a.root {
+		t.Errorf("Intersection of a tree with itself was not shared")
+	}
+}
+
+func bits(n int) int8 {
+	var b int8
+	for ; n > 0; n >>= 1 {
+		b++
+	}
+	return b
+}
+
+func benchmarkSetOp(b *testing.B, op func(x, y *T[Int, int], f func(x, y int) (int, bool)) *T[Int, int]) {
+	for _, sizes := range [][2]int{{1000, 1000}, {100000, 100}, {10000, 10000}} {
+		x := makeTree(0, 2*sizes[0], 2)
+		y := makeTree(0, 3*sizes[1], 3)
+		b.Run(fmt.Sprintf("%dx%d", sizes[0], sizes[1]), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				op(x, y, nil)
+			}
+		})
+	}
+}
+
+func BenchmarkUnionJoin(b *testing.B)   { benchmarkSetOp(b, Union[Int, int, MethodCompare[Int]]) }
+func BenchmarkUnionInsert(b *testing.B) { benchmarkSetOp(b, unionByInsert[Int, int]) }
+func BenchmarkIntersectionJoin(b *testing.B) {
+	benchmarkSetOp(b, Intersection[Int, int, MethodCompare[Int]])
+}
+func BenchmarkIntersectionInsert(b *testing.B) { benchmarkSetOp(b, intersectionByInsert[Int, int]) }
+func BenchmarkDifferenceJoin(b *testing.B) {
+	benchmarkSetOp(b, Difference[Int, int, MethodCompare[Int]])
+}
+func BenchmarkDifferenceInsert(b *testing.B) { benchmarkSetOp(b, differenceByInsert[Int, int]) }
+
+func TestSplitJoin(t *testing.T) {
+	tr := makeTree(0, 500, 1)
+	for _, k := range []Int{-1, 0, 1, 77, 250, 498, 499, 500} {
+		less, d, greater, found := tr.Split(k)
+		inTree := k >= 0 && k < 500
+		if found != inTree || found && d != 10*int(k) {
+			t.Fatalf("Split(%d) found %v, %d", k, found, d)
+		}
+		checkSizes(t, less.root)
+		checkSizes(t, greater.root)
+		if less.Size() != tr.Rank(k) || less.Size()+greater.Size()+tr.Rank(k+1)-tr.Rank(k) != 500 {
+			t.Fatalf("Split(%d) sizes %d, %d", k, less.Size(), greater.Size())
+		}
+		if inTree {
+			greater.Insert(k, d)
+		}
+		if j := Join(less, greater); j.String() != tr.String() {
+			t.Fatalf("Join(Split(%d)) = %v", k, j)
+		}
+	}
+	for _, i := range []int{0, 1, 37, 250, 499, 500, 600} {
+		before, after := tr.SplitAt(i)
+		checkSizes(t, before.root)
+		checkSizes(t, after.root)
+		if before.Size() != min(i, 500) || after.Size() != 500-before.Size() {
+			t.Fatalf("SplitAt(%d) sizes %d, %d", i, before.Size(), after.Size())
+		}
+		if j := Join(before, after); j.String() != tr.String() {
+			t.Fatalf("Join(SplitAt(%d)) = %v", i, j)
+		}
+	}
+	even, odd := tr.Partition(func(k Int, _ int) bool { return k%2 == 0 })
+	checkSizes(t, even.root)
+	checkSizes(t, odd.root)
+	if even.Size() != 250 || odd.Size() != 250 || !even.Contains(42) || !odd.Contains(43) {
+		t.Fatalf("Partition = %v / %v", even, odd)
+	}
+	all, none := tr.Partition(func(Int, int) bool { return true })
+	if all.root != tr.root || none.Size() != 0 {
+		t.Errorf("trivial Partition did not share the tree")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Join of overlapping trees did not panic")
+		}
+	}()
+	Join(tr, tr)
+}
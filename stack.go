@@ -0,0 +1,71 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+// maxHeight bounds the height of every tree.  An AVL tree of height h
+// has at least F(h+2)-1 nodes, where F is the Fibonacci sequence, and
+// subtree sizes are int32, so n < 2**31 < F(47)-1 and h <= 44.  A
+// traversal stack holds at most one node per level, excluding the
+// current one, so it never needs more than maxHeight entries.
+const maxHeight = 44
+
+// A nodeStack is the fixed-size stack used by flat traversals.
+type nodeStack[K any, D any] [maxHeight]*node[K, D]
+
+// push stores n at stack[top] and returns the new top.  A tree that
+// needs a deeper stack is not balanced, so rather than index past the
+// end push panics.
+func (s *nodeStack[K, D]) push(top int, n *node[K, D]) int {
+	if top >= maxHeight {
+		panic("tree is taller than the AVL height bound allows; it is corrupt")
+	}
+	s[top] = n
+	return top + 1
+}
+
+// A Pull iterates over the key and data pairs of a tree from
+// smallest to largest, one call to Next at a time.  Its stack is
+// held inline, so a Pull does not allocate; it is meant to be kept
+// in a local variable or struct field, not passed by value once
+// iteration has begun.
+type Pull[K any, D any] struct {
+	stack nodeStack[K, D]
+	top   int
+}
+
+// Pull returns a pull iterator over t, positioned before its
+// smallest key.
+func (t *Tree[K, D, C]) Pull() Pull[K, D] {
+	var p Pull[K, D]
+	p.reset(t.root)
+	return p
+}
+
+// reset positions p before the smallest key of the tree rooted at n.
+func (p *Pull[K, D]) reset(n *node[K, D]) {
+	p.top = 0
+	for ; n != nil; n = n.left {
+		p.top = p.stack.push(p.top, n)
+	}
+}
+
+// Next returns the next key and data and true, or zero values
+// and false if the iteration is finished.
+func (p *Pull[K, D]) Next() (K, D, bool) {
+	if p.top == 0 {
+		return zero[K](), zero[D](), false
+	}
+	p.top--
+	n := p.stack[p.top]
+	for m := n.right; m != nil; m = m.left {
+		p.top = p.stack.push(p.top, m)
+	}
+	return n.key, n.data, true
+}
+
+// More returns true iff a call to Next will return a key.
+func (p *Pull[K, D]) More() bool {
+	return p.top != 0
+}
@@ -0,0 +1,108 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPull(t *testing.T) {
+	tr := makeTree(0, 1000, 1)
+	var got []Int
+	p := tr.Pull()
+	for k, d, ok := p.Next(); ok; k, d, ok = p.Next() {
+		if d != 10*int(k) {
+			t.Errorf("data for %d = %d", k, d)
+		}
+		got = append(got, k)
+	}
+	if !slices.Equal(got, slices.Collect(tr.Keys())) || p.More() {
+		t.Errorf("Pull visited %d keys, More = %v", len(got), p.More())
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		p := tr.Pull()
+		for p.More() {
+			p.Next()
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Pull allocated %v times", allocs)
+	}
+
+	// The height bound holds for trees as large as sizes allow.
+	if f := fibonacci(maxHeight + 3); f-1 <= 1<<31-1 {
+		t.Errorf("a tree of height %d may have only %d nodes", maxHeight+1, f-1)
+	}
+}
+
+func fibonacci(n int) int {
+	a, b := 0, 1
+	for range n {
+		a, b = b, a+b
+	}
+	return a
+}
+
+func TestStackOverflowPanics(t *testing.T) {
+	// A chain of nodes is not an AVL tree, and is too tall to traverse.
+	var root *node[Int, int]
+	for i := range maxHeight + 2 {
+		root = &node[Int, int]{key: Int(i), left: root}
+	}
+	tr := &T[Int, int]{root: root}
+	for name, f := range map[string]func(){
+		"Pull": func() { tr.Pull() },
+		"All":  func() { tr.All()(func(Int, int) bool { return true }) },
+		"Range": func() {
+			tr.Range(Inclusive[Int](0), Unbounded[Int]())(func(Int, int) bool { return true })
+		},
+	} {
+		func() {
+			defer func() {
+				if r, _ := recover().(string); !strings.Contains(r, "height bound") {
+					t.Errorf("%s on a chain did not panic about its height; got %q", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+const pullSize = 1 << 12
+
+func BenchmarkPull(b *testing.B) {
+	tr := makeTree(0, pullSize, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := tr.Pull()
+		for p.More() {
+			p.Next()
+		}
+	}
+}
+
+func BenchmarkToIter(b *testing.B) {
+	tr := makeTree(0, pullSize, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for it := tr.ToIter(); it.More(); {
+			it.Next()
+		}
+	}
+}
+
+func BenchmarkIterPullDoAll2(b *testing.B) {
+	tr := makeTree(0, pullSize, 1)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		next, stop := iter.Pull2(iter.Seq2[Int, int](tr.DoAll2))
+		for _, _, ok := next(); ok; _, _, ok = next() {
+		}
+		stop()
+	}
+}
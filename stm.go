@@ -0,0 +1,258 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"cmp"
+	"runtime"
+	"slices"
+	"sync/atomic"
+)
+
+// A TVar is a tree that can be read and updated by transactions,
+// which see a consistent view of all the TVars they open and whose
+// updates to them become visible all at once, or not at all.  See
+// Atomically.
+type TVar[K any, D any, C Comparer[K]] struct {
+	current atomic.Pointer[tvVersion[K, D, C]]
+	locked  atomic.Bool // a transaction is committing to this TVar
+	id      uint64      // orders locking, to avoid deadlock
+	eq      func(x, y D) bool
+}
+
+// A tvVersion is one committed version of a TVar, stamped with
+// the value of txClock at its commit.
+type tvVersion[K any, D any, C Comparer[K]] struct {
+	t     Tree[K, D, C]
+	stamp uint64
+}
+
+var (
+	txClock atomic.Uint64 // counts commits
+	tvarIDs atomic.Uint64
+)
+
+// NewTVar returns a TVar whose initial contents are those of t.
+// Later changes to t do not affect it.
+func NewTVar[K any, D comparable, C Comparer[K]](t *Tree[K, D, C]) *TVar[K, D, C] {
+	return NewTVarFunc(t, func(x, y D) bool { return x == y })
+}
+
+// NewTVarFunc is like NewTVar, but compares data with eq when
+// checking a transaction that uses OpenRange for conflicts.
+func NewTVarFunc[K any, D any, C Comparer[K]](t *Tree[K, D, C], eq func(x, y D) bool) *TVar[K, D, C] {
+	v := &TVar[K, D, C]{id: tvarIDs.Add(1), eq: eq}
+	v.current.Store(&tvVersion[K, D, C]{t: *t})
+	return v
+}
+
+// Snapshot returns a copy of the latest committed version of v.
+// Snapshots of different TVars taken this way may not be consistent
+// with one another; open them in a transaction for that.
+func (v *TVar[K, D, C]) Snapshot() *Tree[K, D, C] {
+	return v.current.Load().t.Copy()
+}
+
+// A Tx is a transaction in progress.
+type Tx struct {
+	rv      uint64 // txClock when the transaction began
+	entries map[any]txEntry
+}
+
+// A txEntry is a TVar opened by a transaction.
+type txEntry interface {
+	order() uint64
+	lock()
+	unlock()
+	validate() bool
+	commit(wv uint64)
+}
+
+// txRetry is panicked by a transaction that has seen an
+// inconsistent view, and recovered by Atomically.
+type txRetry struct{}
+
+// Atomically runs f as a transaction, and commits the changes it made
+// to the trees it opened if no other transaction committed conflicting
+// changes in the meantime; if one did, it runs f again.  If f returns
+// an error, nothing is committed and Atomically returns the error.
+//
+// Since f may be run more than once, and may be abandoned at any call
+// to Open or OpenRange, it should not have side effects other than on
+// the trees it opens.
+func Atomically(f func(tx *Tx) error) error {
+	for {
+		tx := &Tx{rv: txClock.Load(), entries: map[any]txEntry{}}
+		done, err := tx.run(f)
+		if err != nil {
+			return err
+		}
+		if done && tx.commit() {
+			return nil
+		}
+		runtime.Gosched()
+	}
+}
+
+// run runs f, and returns false if it must be retried.
+func (tx *Tx) run(f func(tx *Tx) error) (done bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(txRetry); !ok {
+				panic(r)
+			}
+			done, err = false, nil
+		}
+	}()
+	return true, f(tx)
+}
+
+// commit installs the transaction's changes, returning false if
+// it conflicts with another and must be retried.
+func (tx *Tx) commit() bool {
+	es := make([]txEntry, 0, len(tx.entries))
+	for _, e := range tx.entries {
+		es = append(es, e)
+	}
+	slices.SortFunc(es, func(a, b txEntry) int { return cmp.Compare(a.order(), b.order()) })
+	for i, e := range es {
+		e.lock()
+		defer es[i].unlock()
+	}
+	for _, e := range es {
+		if !e.validate() {
+			return false
+		}
+	}
+	wv := txClock.Add(1)
+	for _, e := range es {
+		e.commit(wv)
+	}
+	return true
+}
+
+// A tvEntry is a TVar opened by a transaction: the version it read,
+// the tree that the transaction reads and edits, and the ranges of
+// keys that the transaction depends on, unless it depends on them all.
+type tvEntry[K any, D any, C Comparer[K]] struct {
+	v      *TVar[K, D, C]
+	read   *tvVersion[K, D, C]
+	work   *Tree[K, D, C]
+	whole  bool
+	ranges [][2]Bound[K]
+	next   *Tree[K, D, C] // the version to commit, if changed
+}
+
+// Open returns the tree held by v as of the start of transaction tx,
+// or as tx has since changed it.  Changes that tx makes to the tree are
+// committed with tx.  The transaction conflicts with any other that
+// commits a change to v after tx began.
+func Open[K any, D any, C Comparer[K]](tx *Tx, v *TVar[K, D, C]) *Tree[K, D, C] {
+	e := open(tx, v)
+	e.whole, e.ranges = true, nil
+	return e.work
+}
+
+// OpenRange is like Open, but tx conflicts only with a transaction that
+// commits a change to a key of v that is between lo and hi, or that tx
+// itself changed; a transaction may call OpenRange for several ranges.
+// Otherwise, tx's changes are applied to the version of v that was
+// committed after tx began.  The transaction must not depend on keys
+// outside the ranges it opens.
+func OpenRange[K any, D any, C Comparer[K]](tx *Tx, v *TVar[K, D, C], lo, hi Bound[K]) *Tree[K, D, C] {
+	e := open(tx, v)
+	if !e.whole {
+		e.ranges = append(e.ranges, [2]Bound[K]{lo, hi})
+	}
+	return e.work
+}
+
+func open[K any, D any, C Comparer[K]](tx *Tx, v *TVar[K, D, C]) *tvEntry[K, D, C] {
+	if e, ok := tx.entries[v]; ok {
+		return e.(*tvEntry[K, D, C])
+	}
+	// As in TL2, a TVar that is being committed to, or that was
+	// committed to since tx began, might be inconsistent with what
+	// tx has read already.
+	if v.locked.Load() {
+		panic(txRetry{})
+	}
+	r := v.current.Load()
+	if r.stamp > tx.rv {
+		panic(txRetry{})
+	}
+	e := &tvEntry[K, D, C]{v: v, read: r, work: r.t.Copy()}
+	tx.entries[v] = e
+	return e
+}
+
+func (e *tvEntry[K, D, C]) order() uint64 {
+	return e.v.id
+}
+
+func (e *tvEntry[K, D, C]) lock() {
+	for !e.v.locked.CompareAndSwap(false, true) {
+		runtime.Gosched()
+	}
+}
+
+func (e *tvEntry[K, D, C]) unlock() {
+	e.v.locked.Store(false)
+}
+
+// validate checks that no conflicting version of e's TVar has been
+// committed, and decides what tx will commit to it.
+func (e *tvEntry[K, D, C]) validate() bool {
+	cur := e.v.current.Load()
+	changed := e.work.root != e.read.t.root
+	if cur == e.read {
+		if changed {
+			e.next = e.work
+		}
+		return true
+	}
+	if e.whole {
+		return false
+	}
+	// Find the keys that tx changed, and check that the versions
+	// committed since it began changed none of those, nor any key
+	// in the ranges it opened.  Diff skips shared subtrees, so this
+	// costs time in proportion to the changes.
+	var mine []Change[K, D]
+	if changed {
+		mine = slices.Collect(DiffFunc(&e.read.t, e.work, e.v.eq))
+	}
+	compare := e.work.cmp()
+	for c := range DiffFunc(&e.read.t, &cur.t, e.v.eq) {
+		for _, r := range e.ranges {
+			if aboveLo(r[0], c.Key, compare) && belowHi(r[1], c.Key, compare) {
+				return false
+			}
+		}
+		if _, found := slices.BinarySearchFunc(mine, c.Key, func(m Change[K, D], k K) int {
+			return compare(m.Key, k)
+		}); found {
+			return false
+		}
+	}
+	if len(mine) > 0 {
+		b := cur.t.Transient()
+		for _, c := range mine {
+			if c.Kind == Removed {
+				b.Delete(c.Key)
+			} else {
+				b.Insert(c.Key, c.New)
+			}
+		}
+		e.next = b.Persistent()
+	}
+	return true
+}
+
+func (e *tvEntry[K, D, C]) commit(wv uint64) {
+	if e.next != nil {
+		e.v.current.Store(&tvVersion[K, D, C]{t: *e.next, stamp: wv})
+	}
+}
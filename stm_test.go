@@ -0,0 +1,151 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// TestAtomically keeps a primary index and a secondary index
+// mapping each value back to its key, updating both in every
+// transaction, and checks that no transaction sees one without
+// the other.
+func TestAtomically(t *testing.T) {
+	const keys, writers, perWriter = 64, 8, 200
+	primary := NewTVar(&T[Int, int]{})
+	secondary := NewTVar(&T[Int, int]{})
+	consistent := func(tx *Tx) error {
+		p, s := Open(tx, primary), Open(tx, secondary)
+		if p.Size() != s.Size() {
+			t.Errorf("primary has %d keys, secondary %d", p.Size(), s.Size())
+		}
+		for k, v := range p.All() {
+			if d, ok := s.Get(Int(v)); !ok || d != int(k) {
+				t.Errorf("primary %d:%d, secondary has %d:%d", k, v, v, d)
+			}
+		}
+		return nil
+	}
+
+	var wg, readers sync.WaitGroup
+	stop := make(chan struct{})
+	for range runtime.GOMAXPROCS(0) {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				Atomically(consistent)
+			}
+		}()
+	}
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				k := Int((w*perWriter + i) % keys)
+				v := Int(w*perWriter+i) + 1000
+				Atomically(func(tx *Tx) error {
+					p, s := Open(tx, primary), Open(tx, secondary)
+					if old, ok := p.Get(k); ok {
+						s.Delete(Int(old))
+					}
+					p.Insert(k, int(v))
+					s.Insert(v, int(k))
+					return nil
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	readers.Wait()
+	Atomically(consistent)
+	if n := primary.Snapshot().Size(); n != keys {
+		t.Errorf("primary has %d keys, want %d", n, keys)
+	}
+}
+
+func TestAtomicallyError(t *testing.T) {
+	v := NewTVar(makeTree(0, 10, 1))
+	errStop := errors.New("stop")
+	err := Atomically(func(tx *Tx) error {
+		Open(tx, v).Insert(100, 1000)
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("Atomically returned %v, want %v", err, errStop)
+	}
+	if v.Snapshot().Contains(100) {
+		t.Errorf("changes of a failed transaction were committed")
+	}
+}
+
+// TestOpenRange commits another transaction in the middle of a first
+// one, and checks whether the first must run again.
+func TestOpenRange(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		ranged bool
+		other  Int // the key changed by the other transaction
+		runs   int
+	}{
+		{"whole", false, 80, 2},
+		{"disjoint", true, 80, 1},
+		{"in range", true, 15, 2},
+		{"same key", true, 50, 2},
+	} {
+		v := NewTVar(makeTree(0, 100, 1))
+		runs := 0
+		Atomically(func(tx *Tx) error {
+			runs++
+			var tr *T[Int, int]
+			if tc.ranged {
+				tr = OpenRange(tx, v, Inclusive[Int](10), Exclusive[Int](20))
+			} else {
+				tr = Open(tx, v)
+			}
+			sum := 0
+			for _, d := range tr.Range(Inclusive[Int](10), Exclusive[Int](20)) {
+				sum += d
+			}
+			if runs == 1 {
+				Atomically(func(tx *Tx) error {
+					Open(tx, v).Insert(tc.other, -1)
+					return nil
+				})
+			}
+			tr.Insert(50, sum)
+			return nil
+		})
+		if runs != tc.runs {
+			t.Errorf("%s: transaction ran %d times, want %d", tc.name, runs, tc.runs)
+		}
+		s := v.Snapshot()
+		if err := s.Validate(); err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+		}
+		want := 1450
+		if tc.other == 15 {
+			want += -1 - 150
+		}
+		if d, _ := s.Get(50); d != want {
+			t.Errorf("%s: 50 maps to %d, want %d", tc.name, d, want)
+		}
+		if tc.other != 50 {
+			if d, _ := s.Get(tc.other); d != -1 {
+				t.Errorf("%s: other transaction's change to %d was lost", tc.name, tc.other)
+			}
+		}
+	}
+}
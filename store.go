@@ -0,0 +1,593 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"maps"
+	"os"
+	"path/filepath"
+	"weak"
+)
+
+// A Ref names a tree stored in a Store.  It is the hash of the tree's
+// root node, whose contents include the Refs of its children, so equal
+// Refs mean equal trees.  The zero Ref is the empty tree.
+type Ref [sha256.Size]byte
+
+func (r Ref) String() string {
+	return hex.EncodeToString(r[:])
+}
+
+// A Store keeps trees on disk, writing each node once no matter how
+// many versions contain it, so that saving a new version of a tree
+// writes only the nodes copied since an earlier version was saved or
+// loaded.  Named roots are kept in a separate file, which is replaced
+// atomically so that a crash leaves either the old or the new roots.
+//
+// A Store is a directory holding two files: "nodes", an append-only
+// log of node records, and "roots", a JSON object mapping names to Refs.
+// Each record is the length of its contents, their hash, and the
+// contents: the Refs of the node's children followed by its key and
+// data, each gob-encoded.
+//
+// Load reads every node of a version that is not already in memory,
+// shared with other versions the Store has saved or loaded, and
+// returns an ordinary tree.  OpenLazy instead returns a read-only
+// view of a version that reads each node from disk only when a
+// query first reaches it.
+//
+// A Store is not safe for concurrent use.
+type Store[K any, D any] struct {
+	dir   string
+	nodes *os.File
+	end   int64 // length of the valid part of nodes
+	index map[Ref]record
+	roots map[string]Ref
+	// memo records which nodes in memory are stored, and cache holds
+	// the nodes in memory for each stored Ref; neither keeps nodes alive.
+	memo  map[weak.Pointer[node[K, D]]]Ref
+	cache map[Ref]weak.Pointer[node[K, D]]
+}
+
+// A record locates a node's contents in the nodes file.
+type record struct {
+	off int64
+	len int
+}
+
+const refsLen = 2 * sha256.Size // length of the children's Refs in a record
+
+// rooted and rerootable are satisfied by every *Tree with keys K and data D.
+type rooted[K any, D any] interface {
+	rootNode() *node[K, D]
+}
+
+type rerootable[K any, D any] interface {
+	setRoot(r *node[K, D])
+}
+
+func (t *Tree[K, D, C]) rootNode() *node[K, D] {
+	return t.root
+}
+
+func (t *Tree[K, D, C]) setRoot(r *node[K, D]) {
+	t.root, t.size = r, r.size()
+	t.check()
+}
+
+// OpenStore opens the store in directory dir, creating it if necessary.
+// A record left incomplete by a crash is discarded.
+func OpenStore[K any, D any](dir string) (*Store[K, D], error) {
+	if err := os.MkdirAll(dir, 0o777); err != nil {
+		return nil, err
+	}
+	s := &Store[K, D]{
+		dir:   dir,
+		roots: map[string]Ref{},
+		memo:  map[weak.Pointer[node[K, D]]]Ref{},
+		cache: map[Ref]weak.Pointer[node[K, D]]{},
+	}
+	if b, err := os.ReadFile(s.path("roots")); err == nil {
+		var roots map[string]string
+		if err := json.Unmarshal(b, &roots); err != nil {
+			return nil, fmt.Errorf("reading roots: %w", err)
+		}
+		for name, h := range roots {
+			var r Ref
+			if n, err := hex.Decode(r[:], []byte(h)); err != nil || n != len(r) {
+				return nil, fmt.Errorf("reading roots: bad ref %q for %q", h, name)
+			}
+			s.roots[name] = r
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err := s.openNodes(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store[K, D]) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+// openNodes opens the nodes file and indexes its records,
+// truncating any incomplete record at its end.
+func (s *Store[K, D]) openNodes() error {
+	f, err := os.OpenFile(s.path("nodes"), os.O_RDWR|os.O_CREATE, 0o666)
+	if err != nil {
+		return err
+	}
+	s.nodes, s.end, s.index = f, 0, map[Ref]record{}
+	r := bufio.NewReader(f)
+	for {
+		n, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			break
+		}
+		var ref Ref
+		var contents []byte
+		if err == nil {
+			_, err = io.ReadFull(r, ref[:])
+		}
+		if err == nil {
+			contents = make([]byte, n)
+			_, err = io.ReadFull(r, contents)
+		}
+		if err != nil || sha256.Sum256(contents) != ref {
+			break // incomplete or damaged; the rest is lost
+		}
+		head := int64(len(binary.AppendUvarint(nil, n))) + sha256.Size
+		s.index[ref] = record{s.end + head, int(n)}
+		s.end += head + int64(n)
+	}
+	return f.Truncate(s.end)
+}
+
+// Close closes the store.
+func (s *Store[K, D]) Close() error {
+	return s.nodes.Close()
+}
+
+// Save writes the nodes of t that are not already stored, and returns
+// the Ref for t.  When Save returns, the nodes are safely on disk.
+// If it fails, the store is as it was before.
+func (s *Store[K, D]) Save(t rooted[K, D]) (Ref, error) {
+	if _, err := s.nodes.Seek(s.end, io.SeekStart); err != nil {
+		return Ref{}, err
+	}
+	w := bufio.NewWriter(s.nodes)
+	ps := &pendingSave[K, D]{index: map[Ref]record{}, end: s.end}
+	ref, err := s.save(t.rootNode(), w, ps)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = s.nodes.Sync()
+	}
+	if err != nil {
+		// Nothing written is indexed; drop it, so that a
+		// reopened store does not find part of a record.
+		s.nodes.Truncate(s.end)
+		return Ref{}, err
+	}
+	maps.Copy(s.index, ps.index)
+	s.end = ps.end
+	for _, sn := range ps.saved {
+		s.memo[sn.p] = sn.ref
+		s.cache[sn.ref] = sn.p
+	}
+	return ref, nil
+}
+
+// A pendingSave holds what a Save has written, to be recorded in the
+// Store only once it is safely on disk.
+type pendingSave[K any, D any] struct {
+	index map[Ref]record
+	end   int64
+	saved []savedNode[K, D]
+}
+
+type savedNode[K any, D any] struct {
+	p   weak.Pointer[node[K, D]]
+	ref Ref
+}
+
+func (s *Store[K, D]) save(n *node[K, D], w *bufio.Writer, ps *pendingSave[K, D]) (Ref, error) {
+	if n == nil {
+		return Ref{}, nil
+	}
+	p := weak.Make(n)
+	if ref, ok := s.memo[p]; ok {
+		return ref, nil
+	}
+	left, err := s.save(n.left, w, ps)
+	if err != nil {
+		return Ref{}, err
+	}
+	right, err := s.save(n.right, w, ps)
+	if err != nil {
+		return Ref{}, err
+	}
+	var buf bytes.Buffer
+	buf.Write(left[:])
+	buf.Write(right[:])
+	if err := gob.NewEncoder(&buf).Encode(n.key); err != nil {
+		return Ref{}, err
+	}
+	if err := gob.NewEncoder(&buf).Encode(n.data); err != nil {
+		return Ref{}, err
+	}
+	contents := buf.Bytes()
+	ref := Ref(sha256.Sum256(contents))
+	_, stored := s.index[ref]
+	if _, written := ps.index[ref]; !stored && !written {
+		head := binary.AppendUvarint(nil, uint64(len(contents)))
+		head = append(head, ref[:]...)
+		if _, err := w.Write(head); err != nil {
+			return Ref{}, err
+		}
+		if _, err := w.Write(contents); err != nil {
+			return Ref{}, err
+		}
+		ps.index[ref] = record{ps.end + int64(len(head)), len(contents)}
+		ps.end += int64(len(head) + len(contents))
+	}
+	ps.saved = append(ps.saved, savedNode[K, D]{p, ref})
+	return ref, nil
+}
+
+// Load replaces the contents of t, which is a *Tree (or *T, *Ordered
+// or *Func) with keys K and data D, by the tree stored as ref.
+func (s *Store[K, D]) Load(ref Ref, t rerootable[K, D]) error {
+	r, err := s.load(ref)
+	if err != nil {
+		return err
+	}
+	t.setRoot(r)
+	return nil
+}
+
+func (s *Store[K, D]) load(ref Ref) (*node[K, D], error) {
+	if ref == (Ref{}) {
+		return nil, nil
+	}
+	if p, ok := s.cache[ref]; ok {
+		if n := p.Value(); n != nil {
+			return n, nil
+		}
+	}
+	n := &node[K, D]{}
+	left, right, err := s.decode(ref, &n.key, &n.data)
+	if err != nil {
+		return nil, err
+	}
+	if n.left, err = s.load(left); err != nil {
+		return nil, err
+	}
+	if n.right, err = s.load(right); err != nil {
+		return nil, err
+	}
+	n.fix()
+	p := weak.Make(n)
+	s.memo[p] = ref
+	s.cache[ref] = p
+	return n, nil
+}
+
+// decode reads the record for ref into key and data,
+// and returns the Refs of its children.
+func (s *Store[K, D]) decode(ref Ref, key *K, data *D) (left, right Ref, err error) {
+	contents, err := s.read(ref)
+	if err != nil {
+		return Ref{}, Ref{}, err
+	}
+	dec := bytes.NewReader(contents[refsLen:])
+	if err := gob.NewDecoder(dec).Decode(key); err != nil {
+		return Ref{}, Ref{}, fmt.Errorf("node %v: decoding key: %w", ref, err)
+	}
+	if err := gob.NewDecoder(dec).Decode(data); err != nil {
+		return Ref{}, Ref{}, fmt.Errorf("node %v: decoding data: %w", ref, err)
+	}
+	return Ref(contents[:sha256.Size]), Ref(contents[sha256.Size:refsLen]), nil
+}
+
+// read returns the contents of the record for ref.
+func (s *Store[K, D]) read(ref Ref) ([]byte, error) {
+	rec, ok := s.index[ref]
+	if !ok {
+		return nil, fmt.Errorf("node %v is not in the store", ref)
+	}
+	contents := make([]byte, rec.len)
+	if _, err := s.nodes.ReadAt(contents, rec.off); err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// Root returns the Ref last set for name, and false if there is none.
+func (s *Store[K, D]) Root(name string) (Ref, bool) {
+	ref, ok := s.roots[name]
+	return ref, ok
+}
+
+// SetRoot records ref, which must have been saved, as the root called
+// name.  When SetRoot returns the change is safely on disk; if it is
+// interrupted by a crash, the roots are as they were before.
+func (s *Store[K, D]) SetRoot(name string, ref Ref) error {
+	if _, ok := s.index[ref]; !ok && ref != (Ref{}) {
+		return fmt.Errorf("SetRoot %s: node %v is not in the store", name, ref)
+	}
+	old, had := s.roots[name]
+	s.roots[name] = ref
+	if err := s.writeRoots(); err != nil {
+		if had {
+			s.roots[name] = old
+		} else {
+			delete(s.roots, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteRoot removes the root called name, so that its nodes
+// may be discarded by Compact.
+func (s *Store[K, D]) DeleteRoot(name string) error {
+	old, had := s.roots[name]
+	if !had {
+		return nil
+	}
+	delete(s.roots, name)
+	if err := s.writeRoots(); err != nil {
+		s.roots[name] = old
+		return err
+	}
+	return nil
+}
+
+func (s *Store[K, D]) writeRoots() error {
+	roots := map[string]string{}
+	for name, ref := range s.roots {
+		roots[name] = ref.String()
+	}
+	b, err := json.Marshal(roots)
+	if err != nil {
+		return err
+	}
+	return s.replace("roots", func(f *os.File) error {
+		_, err := f.Write(b)
+		return err
+	})
+}
+
+// replace atomically replaces the file called name by one written by fill.
+func (s *Store[K, D]) replace(name string, fill func(f *os.File) error) error {
+	f, err := os.CreateTemp(s.dir, name+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // fails harmlessly after the rename
+	err = fill(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), s.path(name))
+	}
+	if err == nil {
+		err = syncDir(s.dir)
+	}
+	return err
+}
+
+// syncDir makes a rename in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Compact rewrites the nodes file to hold only the nodes reachable
+// from the named roots, discarding those of every other version.
+func (s *Store[K, D]) Compact() error {
+	live := map[Ref]bool{}
+	var order []Ref // children before parents, as Save writes them
+	var mark func(ref Ref) error
+	mark = func(ref Ref) error {
+		if ref == (Ref{}) || live[ref] {
+			return nil
+		}
+		live[ref] = true
+		contents, err := s.read(ref)
+		if err != nil {
+			return err
+		}
+		if err := mark(Ref(contents[:sha256.Size])); err != nil {
+			return err
+		}
+		if err := mark(Ref(contents[sha256.Size:refsLen])); err != nil {
+			return err
+		}
+		order = append(order, ref)
+		return nil
+	}
+	for _, ref := range s.roots {
+		if err := mark(ref); err != nil {
+			return err
+		}
+	}
+	err := s.replace("nodes", func(f *os.File) error {
+		w := bufio.NewWriter(f)
+		for _, ref := range order {
+			contents, err := s.read(ref)
+			if err != nil {
+				return err
+			}
+			head := binary.AppendUvarint(nil, uint64(len(contents)))
+			w.Write(head)
+			w.Write(ref[:])
+			w.Write(contents)
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return err
+	}
+	s.nodes.Close()
+	if err := s.openNodes(); err != nil {
+		return err
+	}
+	// Forget nodes that are gone from the file, or from memory.
+	for p, ref := range s.memo {
+		if _, ok := s.index[ref]; !ok || p.Value() == nil {
+			delete(s.memo, p)
+		}
+	}
+	for ref, p := range s.cache {
+		if _, ok := s.index[ref]; !ok || p.Value() == nil {
+			delete(s.cache, ref)
+		}
+	}
+	return nil
+}
+
+// A Lazy is a read-only view of a tree in a Store, whose nodes are
+// read from disk when a query first reaches them, and kept in memory
+// thereafter.  A lookup in a large stored tree reads only the nodes on
+// its path.  A Lazy shares the Store's file, and so like the Store is
+// not safe for concurrent use; nor may it be used after the Store is
+// closed or compacted.
+//
+// Since iterators cannot return errors, a Lazy records the first error
+// it encounters in reading a node, after which its queries behave as if
+// the node were absent; check Err after querying.
+type Lazy[K any, D any, C Comparer[K]] struct {
+	s     *Store[K, D]
+	root  *lazyNode[K, D]
+	order C
+	err   error
+}
+
+// A lazyNode is a stored node, with its key, data and children
+// present once it is loaded.  A nil lazyNode is an empty subtree.
+type lazyNode[K any, D any] struct {
+	ref         Ref
+	loaded      bool
+	key         K
+	data        D
+	left, right *lazyNode[K, D]
+}
+
+// OpenLazy returns a Lazy view of the tree stored as ref, whose keys
+// are ordered as t's are.  It reads nothing from disk.
+func OpenLazy[K any, D any, C Comparer[K]](s *Store[K, D], ref Ref, t *Tree[K, D, C]) *Lazy[K, D, C] {
+	return &Lazy[K, D, C]{s: s, root: newLazyNode[K, D](ref), order: t.order}
+}
+
+func newLazyNode[K any, D any](ref Ref) *lazyNode[K, D] {
+	if ref == (Ref{}) {
+		return nil
+	}
+	return &lazyNode[K, D]{ref: ref}
+}
+
+// fault loads n if it is not yet loaded, and returns false if it
+// cannot be, or an earlier error has been recorded.
+func (l *Lazy[K, D, C]) fault(n *lazyNode[K, D]) bool {
+	if l.err != nil {
+		return false
+	}
+	if n.loaded {
+		return true
+	}
+	left, right, err := l.s.decode(n.ref, &n.key, &n.data)
+	if err != nil {
+		l.err = err
+		return false
+	}
+	n.left, n.right, n.loaded = newLazyNode[K, D](left), newLazyNode[K, D](right), true
+	return true
+}
+
+// Err returns the first error encountered in reading a node, or nil.
+func (l *Lazy[K, D, C]) Err() error {
+	return l.err
+}
+
+// Get returns the data for x and true, or false if x is not present.
+func (l *Lazy[K, D, C]) Get(x K) (D, bool) {
+	compare := l.order.Compare
+	for n := l.root; n != nil && l.fault(n); {
+		switch c := compare(x, n.key); {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return n.data, true
+		}
+	}
+	return zero[D](), false
+}
+
+// Contains returns true iff x is a key in the tree.
+func (l *Lazy[K, D, C]) Contains(x K) bool {
+	_, ok := l.Get(x)
+	return ok
+}
+
+// All returns an iterator over the key and data pairs in the tree,
+// ordered from smallest to largest key.
+func (l *Lazy[K, D, C]) All() iter.Seq2[K, D] {
+	return l.Range(Unbounded[K](), Unbounded[K]())
+}
+
+// Range returns an iterator over the key and data pairs in the tree
+// with keys between lo and hi, ordered from smallest to largest.
+// It reads only the nodes it must to find them.
+func (l *Lazy[K, D, C]) Range(lo, hi Bound[K]) iter.Seq2[K, D] {
+	return func(yield func(k K, d D) bool) {
+		l.doRange(l.root, lo, hi, l.order.Compare, yield)
+	}
+}
+
+func (l *Lazy[K, D, C]) doRange(n *lazyNode[K, D], lo, hi Bound[K], compare func(a, b K) int, yield func(k K, d D) bool) bool {
+	if n == nil {
+		return true
+	}
+	if !l.fault(n) {
+		return false
+	}
+	above, below := aboveLo(lo, n.key, compare), belowHi(hi, n.key, compare)
+	if above && !l.doRange(n.left, lo, hi, compare, yield) {
+		return false
+	}
+	if above && below && !yield(n.key, n.data) {
+		return false
+	}
+	return !below || l.doRange(n.right, lo, hi, compare, yield)
+}
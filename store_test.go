@@ -0,0 +1,221 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenStore[Int, int](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := makeTree(0, 1000, 1)
+	r1, err := s.Save(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.index) != 1000 {
+		t.Errorf("saving 1000 nodes stored %d", len(s.index))
+	}
+
+	// A new version writes only its copied path.
+	v2 := v1.Copy()
+	v2.Insert(500, -1)
+	r2, err := s.Save(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, h := len(s.index)-1000, int(v1.root.height()); n > h || n == 0 {
+		t.Errorf("saving a one-key change stored %d nodes, tree height %d", n, h)
+	}
+	if r, _ := s.Save(v2.Copy()); r != r2 {
+		t.Errorf("saving the same tree again gave a different Ref")
+	}
+	if err := s.SetRoot("v1", r1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetRoot("v2", r2); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// Reopen, after a crash that left a partial record behind.
+	f, _ := os.OpenFile(filepath.Join(dir, "nodes"), os.O_WRONLY|os.O_APPEND, 0)
+	f.Write([]byte{40, 1, 2, 3})
+	f.Close()
+	s, err = OpenStore[Int, int](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	load := func(name string, want *T[Int, int]) *T[Int, int] {
+		t.Helper()
+		ref, ok := s.Root(name)
+		if !ok {
+			t.Fatalf("no root %s", name)
+		}
+		got := &T[Int, int]{}
+		if err := s.Load(ref, got); err != nil {
+			t.Fatalf("Load(%s): %v", name, err)
+		}
+		if !Equals(got, want) {
+			t.Errorf("Load(%s) = %v", name, got)
+		}
+		if err := got.Validate(); err != nil {
+			t.Errorf("Load(%s): %v", name, err)
+		}
+		return got
+	}
+	l1 := load("v1", v1)
+	l2 := load("v2", v2)
+	// The loaded versions share the nodes they have in common.
+	compared := 0
+	for range DiffFunc(l1, l2, func(x, y int) bool { compared++; return x == y }) {
+	}
+	if compared > int(l1.root.height()) {
+		t.Errorf("loaded versions share little; diff compared %d entries", compared)
+	}
+
+	// Compaction keeps just the nodes of the remaining roots.
+	if err := s.DeleteRoot("v1"); err != nil {
+		t.Fatal(err)
+	}
+	l1, l2 = nil, nil
+	if err := s.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.index) != 1000 {
+		t.Errorf("after Compact, store holds %d nodes, want 1000", len(s.index))
+	}
+	load("v2", v2)
+	// A version saved after compaction is complete, even where it
+	// shares nodes with a dropped one.
+	r3, err := s.Save(v1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.SetRoot("v1", r3)
+	s.Close()
+	s, _ = OpenStore[Int, int](dir)
+	load("v1", v1)
+	load("v2", v2)
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("store directory holds %d files, want 2 (nodes and roots)", len(entries))
+	}
+}
+
+// countLoaded returns the number of nodes of n that have been read.
+func countLoaded[K any, D any](n *lazyNode[K, D]) int {
+	if n == nil || !n.loaded {
+		return 0
+	}
+	return 1 + countLoaded(n.left) + countLoaded(n.right)
+}
+
+func TestStoreLazy(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenStore[Int, int](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	tr := makeTree(0, 1000, 1)
+	ref, err := s.Save(tr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := OpenLazy(s, ref, tr)
+	if countLoaded(l.root) != 0 {
+		t.Errorf("OpenLazy read nodes")
+	}
+	if d, ok := l.Get(500); !ok || d != 5000 {
+		t.Errorf("Get(500) = %d, %v", d, ok)
+	}
+	if l.Contains(1000) {
+		t.Errorf("Contains(1000) is true")
+	}
+	if n, h := countLoaded(l.root), int(tr.root.height()); n > 2*h {
+		t.Errorf("two lookups read %d nodes, tree height %d", n, h)
+	}
+	var keys []Int
+	for k := range l.Range(Inclusive[Int](10), Exclusive[Int](20)) {
+		keys = append(keys, k)
+	}
+	if len(keys) != 10 || keys[0] != 10 || keys[9] != 19 {
+		t.Errorf("Range(10, 20) = %v", keys)
+	}
+	if n := countLoaded(l.root); n > 10+4*int(tr.root.height()) {
+		t.Errorf("after a short Range, %d nodes are read", n)
+	}
+	i := 0
+	for k, d := range l.All() {
+		if int(k) != i || d != 10*i {
+			t.Fatalf("All yielded %d:%d at %d", k, d, i)
+		}
+		i++
+	}
+	if i != 1000 || countLoaded(l.root) != 1000 || l.Err() != nil {
+		t.Errorf("All yielded %d, read %d nodes, err %v", i, countLoaded(l.root), l.Err())
+	}
+
+	bad := OpenLazy(s, Ref{1}, tr)
+	if bad.Contains(1) || bad.Err() == nil {
+		t.Errorf("Lazy view of a missing Ref reported no error")
+	}
+}
+
+// TestStoreSaveFailure checks that a Save that fails to write
+// leaves nothing recorded, so that a later Save writes every node.
+func TestStoreSaveFailure(t *testing.T) {
+	dir := t.TempDir()
+	s, err := OpenStore[Int, int](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := makeTree(0, 100, 1)
+	if _, err := s.Save(v1); err != nil {
+		t.Fatal(err)
+	}
+	v2 := v1.Copy()
+	v2.Insert(1000, 1)
+	n := len(s.index)
+
+	// Make writes fail, by swapping in a read-only handle.
+	rw := s.nodes
+	s.nodes, err = os.Open(filepath.Join(dir, "nodes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Save(v2); err == nil {
+		t.Fatalf("Save to a read-only file succeeded")
+	}
+	s.nodes.Close()
+	s.nodes = rw
+	if len(s.index) != n {
+		t.Errorf("failed Save indexed %d nodes", len(s.index)-n)
+	}
+
+	r2, err := s.Save(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	s, err = OpenStore[Int, int](dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	got := &T[Int, int]{}
+	if err := s.Load(r2, got); err != nil || !Equals(got, v2) {
+		t.Errorf("after a failed Save, Load of a later one = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+// An edit identifies the nodes owned by a Builder, which it may
+// modify in place.  It must not be a zero-sized type, so that
+// distinct edits have distinct addresses.
+type edit struct {
+	_ byte
+}
+
+// A Builder is a transient (mutable) version of a Tree, for applying
+// a batch of edits cheaply.  Nodes that a Builder creates or copies
+// are marked as its own and are thereafter modified in place, so a
+// run of edits to the same region of the tree allocates only once.
+// Trees that the Builder started from or handed out are never modified.
+//
+// A Builder is not safe for concurrent use.
+type Builder[K any, D any, C Comparer[K]] struct {
+	t    Tree[K, D, C]
+	edit *edit
+}
+
+// Transient returns a Builder initially containing the elements of t.
+// t is not changed by edits to the Builder.
+func (t *Tree[K, D, C]) Transient() *Builder[K, D, C] {
+	return &Builder[K, D, C]{t: *t, edit: new(edit)}
+}
+
+// Persistent returns a Tree containing the current elements of b.
+// b remains usable, but will no longer modify any of the nodes
+// it owned, since they now belong to the returned tree.
+func (b *Builder[K, D, C]) Persistent() *Tree[K, D, C] {
+	b.edit = new(edit)
+	return b.t.Copy()
+}
+
+// Insert is like Tree.Insert.
+func (b *Builder[K, D, C]) Insert(x K, data D) D {
+	return b.t.insert(x, data, b.edit)
+}
+
+// Delete is like Tree.Delete.
+func (b *Builder[K, D, C]) Delete(x K) D {
+	d, _ := b.t.deleteOk(x, b.edit)
+	return d
+}
+
+// DeleteOk is like Tree.DeleteOk.
+func (b *Builder[K, D, C]) DeleteOk(x K) (D, bool) {
+	return b.t.deleteOk(x, b.edit)
+}
+
+// DeleteMin is like Tree.DeleteMin.
+func (b *Builder[K, D, C]) DeleteMin() (K, D) {
+	k, d, _ := b.t.deleteMinOk(b.edit)
+	return k, d
+}
+
+// DeleteMax is like Tree.DeleteMax.
+func (b *Builder[K, D, C]) DeleteMax() (K, D) {
+	k, d, _ := b.t.deleteMaxOk(b.edit)
+	return k, d
+}
+
+// Get is like Tree.Get.
+func (b *Builder[K, D, C]) Get(x K) (D, bool) {
+	return b.t.Get(x)
+}
+
+// Contains is like Tree.Contains.
+func (b *Builder[K, D, C]) Contains(x K) bool {
+	return b.t.Contains(x)
+}
+
+// Size is like Tree.Size.
+func (b *Builder[K, D, C]) Size() int {
+	return b.t.Size()
+}
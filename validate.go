@@ -0,0 +1,76 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import "fmt"
+
+// Validate checks that t is a well-formed AVL tree: that its keys are
+// in strictly increasing order, that every node's height and size agree
+// with its children, that no node's subtrees differ in height by more
+// than one, and that t's size is the number of its nodes.  It returns
+// an error describing the first violation found, including the keys on
+// the path from the root to the offending node, or nil.
+func (t *Tree[K, D, C]) Validate() error {
+	var path []K
+	n, err := t.root.validate(nil, nil, t.cmp(), &path)
+	if err != nil {
+		return err
+	}
+	if n != t.size {
+		return fmt.Errorf("tree size is %d, but it has %d nodes", t.size, n)
+	}
+	return nil
+}
+
+// validate checks the subtree t, all of whose keys must lie strictly
+// between lo and hi where those are not nil, and returns its node count.
+// path holds the keys from the root to t's parent.
+func (t *node[K, D]) validate(lo, hi *K, compare func(a, b K) int, path *[]K) (int, error) {
+	if t == nil {
+		return 0, nil
+	}
+	*path = append(*path, t.key)
+	defer func() { *path = (*path)[:len(*path)-1] }()
+
+	fail := func(format string, args ...any) (int, error) {
+		return 0, fmt.Errorf("at key path %v: %s", *path, fmt.Sprintf(format, args...))
+	}
+	if lo != nil && compare(*lo, t.key) >= 0 {
+		return fail("key %v is not greater than %v", t.key, *lo)
+	}
+	if hi != nil && compare(t.key, *hi) >= 0 {
+		return fail("key %v is not less than %v", t.key, *hi)
+	}
+	ln, err := t.left.validate(lo, &t.key, compare, path)
+	if err != nil {
+		return 0, err
+	}
+	rn, err := t.right.validate(&t.key, hi, compare, path)
+	if err != nil {
+		return 0, err
+	}
+	lh, rh := t.left.height(), t.right.height()
+	if h := 1 + max(lh, rh); t.height_ != h {
+		return fail("height is %d, want %d", t.height_, h)
+	}
+	if b := rh - lh; b < -1 || b > 1 {
+		return fail("balance factor is %d", b)
+	}
+	if n := 1 + ln + rn; t.size() != n {
+		return fail("size is %d, but the subtree has %d nodes", t.size(), n)
+	}
+	return 1 + ln + rn, nil
+}
+
+// check panics if t is not valid and validation after every
+// mutation is enabled by the avldebug build tag.
+func (t *Tree[K, D, C]) check() *Tree[K, D, C] {
+	if debugValidate {
+		if err := t.Validate(); err != nil {
+			panic(err)
+		}
+	}
+	return t
+}
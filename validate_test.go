@@ -0,0 +1,45 @@
+// Copyright 2026 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package iter_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tr := makeTree(0, 200, 1)
+	for i := 0; i < 200; i += 3 {
+		tr.Delete(Int(i))
+		if err := tr.Validate(); err != nil {
+			t.Fatalf("after Delete(%d): %v", i, err)
+		}
+	}
+	if err := (&T[Int, int]{}).Validate(); err != nil {
+		t.Errorf("empty tree: %v", err)
+	}
+
+	corrupt := func(f func(tr *T[Int, int])) error {
+		tr := makeTree(0, 15, 1) // perfectly balanced, root 7
+		f(tr)
+		return tr.Validate()
+	}
+	for _, tc := range []struct {
+		name string
+		f    func(tr *T[Int, int])
+		want string
+	}{
+		{"order", func(tr *T[Int, int]) { tr.root.left.right.key = 9 }, "key path [7 3 9]: key 9 is not less than 7"},
+		{"height", func(tr *T[Int, int]) { tr.root.right.left.height_ = 3 }, "key path [7 11 9]: height is 3, want 2"},
+		{"balance", func(tr *T[Int, int]) { tr.root.left.left = nil; tr.root.left.height_ = 3 }, "key path [7 3]: balance factor is 2"},
+		{"node size", func(tr *T[Int, int]) { tr.root.right.size_ = 1 }, "key path [7 11]: size is 1"},
+		{"tree size", func(tr *T[Int, int]) { tr.size = 14 }, "tree size is 14, but it has 15 nodes"},
+	} {
+		err := corrupt(tc.f)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: Validate() = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+}